@@ -0,0 +1,146 @@
+package pangu
+
+import (
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// androidEscape matches references, Android escape sequences and format
+// specifiers in the text of a string resource.
+var androidEscape = regexp.MustCompile(xmlReference.String() + `|\\u[0-9a-fA-F]{4}|\\.|` + placeholder.String())
+
+// SpacingAndroidXML performs paranoid text spacing on an Android string
+// resource file, such as res/values-zh-rTW/strings.xml. Only the values
+// of <string>, and of the <item>s of <string-array> and <plurals>, are
+// changed; resources with translatable="false" are skipped. XML escaping,
+// backslash escapes, format specifiers such as %1$s, CDATA sections,
+// comments and <xliff:g> placeholders are left alone, as is everything
+// else, byte for byte. Files whose root element is not <resources> are
+// returned unchanged.
+func (s *Spacer) SpacingAndroidXML(src []byte) ([]byte, error) {
+	tokens, err := xmlTokens(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var out strings.Builder
+	var stack []string
+	for i := 0; i < len(tokens); i++ {
+		switch tok := tokens[i].Token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && tok.Name.Local != "resources" {
+				return src, nil
+			}
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if isAndroidValue(tok, parent) {
+				end := closing(tokens, i)
+				out.WriteString(tokens[i].raw)
+				out.WriteString(s.spacingSegments(androidSegments(tokens[i+1 : end])))
+				if end < len(tokens) {
+					out.WriteString(tokens[end].raw)
+				}
+				i = end
+				continue
+			}
+			stack = append(stack, tok.Name.Local)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		out.WriteString(tokens[i].raw)
+	}
+
+	return s.suppressed(src, []byte(out.String()), markupDirective), nil
+}
+
+func isAndroidValue(tok xml.StartElement, parent string) bool {
+	for _, attr := range tok.Attr {
+		if attr.Name.Local == "translatable" && attr.Value == "false" {
+			return false
+		}
+	}
+
+	switch tok.Name.Local {
+	case "string":
+		return parent == "resources"
+	case "item":
+		return parent == "string-array" || parent == "plurals"
+	}
+
+	return false
+}
+
+// closing returns the index of the token closing the element started at
+// tokens[i], or len(tokens) if it is never closed.
+func closing(tokens []xmlToken, i int) int {
+	depth := 0
+	for j := i + 1; j < len(tokens); j++ {
+		switch tokens[j].Token.(type) {
+		case xml.StartElement:
+			// Self-closing elements get an EndElement of their own too.
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return j
+			}
+			depth--
+		}
+	}
+
+	return len(tokens)
+}
+
+// androidSegments turns the content of a string resource into segments.
+func androidSegments(tokens []xmlToken) []segment {
+	var segs []segment
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch t := tok.Token.(type) {
+		case xml.CharData:
+			if tok.isCDATA() {
+				segs = append(segs, cdataSegments(tok.raw)...)
+			} else {
+				segs = append(segs, splitSegments(tok.raw, androidEscape, decodeAndroid)...)
+			}
+		case xml.StartElement:
+			if t.Name.Space == "xliff" && t.Name.Local == "g" {
+				// A placeholder, seen as the text it holds.
+				raw, text, end := element(tokens, i)
+				segs = append(segs, opaque(raw, text))
+				i = end
+				continue
+			}
+			segs = append(segs, segment{raw: tok.raw, open: true})
+		default:
+			segs = append(segs, opaque(tok.raw, ""))
+		}
+	}
+
+	return segs
+}
+
+func decodeAndroid(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, "&"):
+		return html.UnescapeString(raw)
+	case strings.HasPrefix(raw, `\u`):
+		if r, err := strconv.ParseUint(raw[2:], 16, 32); err == nil {
+			return string(rune(r))
+		}
+	case raw == `\n`:
+		return "\n"
+	case raw == `\t`:
+		return "\t"
+	case strings.HasPrefix(raw, `\`):
+		return raw[1:]
+	}
+
+	return raw
+}
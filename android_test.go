@@ -0,0 +1,80 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type AndroidTestSuite struct {
+	suite.Suite
+}
+
+func TestAndroidTestSuite(t *testing.T) {
+	suite.Run(t, new(AndroidTestSuite))
+}
+
+func (suite *AndroidTestSuite) TestSpacingAndroidXML() {
+	src := `<?xml version="1.0" encoding="utf-8"?>
+<!-- 應用程式App字串 -->
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="app_name">與PM戰鬥的人</string>
+    <string name="greeting">你好%1$s，你有%2$d則訊息</string>
+    <string name="escaped">他說\"PM\"是敵人&amp;Bug也是</string>
+    <string name="styled">當你凝視著<b>bug</b>，bug也凝視著你</string>
+    <string name="cdata"><![CDATA[請看<a href="https://example.com/中文abc">說明Doc</a>]]></string>
+    <string name="count">共<xliff:g id="count" example="3">%d</xliff:g>個檔案</string>
+    <string name="key中文" translatable="false">不要改PM</string>
+    <string-array name="planets">
+        <item>水星Mercury</item>
+    </string-array>
+    <plurals name="files">
+        <item quantity="other">%d個檔案</item>
+    </plurals>
+</resources>
+`
+	out, err := pangu.NewSpacer().SpacingAndroidXML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<?xml version="1.0" encoding="utf-8"?>
+<!-- 應用程式App字串 -->
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="app_name">與 PM 戰鬥的人</string>
+    <string name="greeting">你好 %1$s，你有 %2$d 則訊息</string>
+    <string name="escaped">他說 \"PM\" 是敵人 &amp; Bug 也是</string>
+    <string name="styled">當你凝視著 <b>bug</b>，bug 也凝視著你</string>
+    <string name="cdata"><![CDATA[請看<a href="https://example.com/中文abc">說明 Doc</a>]]></string>
+    <string name="count">共 <xliff:g id="count" example="3">%d</xliff:g> 個檔案</string>
+    <string name="key中文" translatable="false">不要改PM</string>
+    <string-array name="planets">
+        <item>水星 Mercury</item>
+    </string-array>
+    <plurals name="files">
+        <item quantity="other">%d 個檔案</item>
+    </plurals>
+</resources>
+`, string(out))
+}
+
+func (suite *AndroidTestSuite) TestOtherXML() {
+	src := `<plist><string>與PM戰鬥的人</string></plist>`
+	out, err := pangu.NewSpacer().SpacingAndroidXML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(src, string(out))
+}
+
+func (suite *AndroidTestSuite) TestSelfClosingElement() {
+	src := `<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="count">共<xliff:g id="count"/>個PM檔案</string>
+    <string name="break">第一行<br/>與PM戰鬥的人</string>
+    <string name="next">當你凝視著bug</string>
+</resources>
+`
+	out, err := pangu.NewSpacer().SpacingAndroidXML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="count">共<xliff:g id="count"/>個 PM 檔案</string>
+    <string name="break">第一行<br/>與 PM 戰鬥的人</string>
+    <string name="next">當你凝視著 bug</string>
+</resources>
+`, string(out))
+}
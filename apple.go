@@ -0,0 +1,104 @@
+package pangu
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// appleEscape matches the escape sequences and format specifiers in the
+// strings of .strings and .xcstrings files.
+var appleEscape = regexp.MustCompile(`\\U[0-9a-fA-F]{4}|\\u[0-9a-fA-F]{4}|\\.|` + placeholder.String())
+
+// SpacingAppleStrings performs paranoid text spacing on an Apple .strings
+// file, such as zh-Hant.lproj/Localizable.strings. Only the values of
+// "key" = "value"; pairs are changed. Keys, comments, escape sequences
+// and format specifiers such as %@ or %1$@ are left alone, as is
+// everything else, byte for byte.
+func (s *Spacer) SpacingAppleStrings(src []byte) []byte {
+	text := string(src)
+	var out strings.Builder
+	last, value := 0, false
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end + 4
+			}
+		case strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexAny(text[i:], "\r\n")
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end
+			}
+		case text[i] == '"':
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				i = len(text)
+				break
+			}
+			if value {
+				out.WriteString(text[last : i+1])
+				out.WriteString(s.spacingSegments(appleSegments(text[i+1 : end])))
+				last = end
+			}
+			value = false
+			i = end + 1
+		case text[i] == '=':
+			value = true
+			i++
+		case text[i] == ';':
+			value = false
+			i++
+		default:
+			i++
+		}
+	}
+	out.WriteString(text[last:])
+
+	return s.suppressed(src, []byte(out.String()), cDirective)
+}
+
+// SpacingXCStrings performs paranoid text spacing on an Xcode string
+// catalog (.xcstrings). Only the values of the localizations are changed,
+// plural and device variations included; keys, which are the source
+// strings, and everything else are left alone, byte for byte.
+func (s *Spacer) SpacingXCStrings(src []byte) ([]byte, error) {
+	out, err := s.spacingJSON(src, appleSegments, []string{"strings.*.localizations.**.stringUnit.value"})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.suppressed(src, out, nil), nil
+}
+
+func appleSegments(body string) []segment {
+	return splitSegments(body, appleEscape, decodeApple)
+}
+
+func decodeApple(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, `\U`), strings.HasPrefix(raw, `\u`):
+		if r, err := strconv.ParseUint(raw[2:], 16, 32); err == nil {
+			return string(rune(r))
+		}
+	case raw == `\n`:
+		return "\n"
+	case raw == `\t`:
+		return "\t"
+	case strings.HasPrefix(raw, `\`):
+		return raw[1:]
+	}
+
+	return raw
+}
@@ -0,0 +1,71 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type AppleTestSuite struct {
+	suite.Suite
+}
+
+func TestAppleTestSuite(t *testing.T) {
+	suite.Run(t, new(AppleTestSuite))
+}
+
+func (suite *AppleTestSuite) TestSpacingAppleStrings() {
+	src := "/* 標題Title */\n" +
+		"\"title\" = \"與PM戰鬥的人\";\n" +
+		"// 問候greeting\n" +
+		"\"greeting %@\" = \"你好%@，你有%1$lld則訊息\";\n" +
+		"\"escaped\" = \"他說\\\"PM\\\"是\\U6575人\\n第二行line\";\n"
+
+	suite.Equal("/* 標題Title */\n"+
+		"\"title\" = \"與 PM 戰鬥的人\";\n"+
+		"// 問候greeting\n"+
+		"\"greeting %@\" = \"你好 %@，你有 %1$lld 則訊息\";\n"+
+		"\"escaped\" = \"他說 \\\"PM\\\" 是\\U6575人\\n第二行 line\";\n", string(pangu.NewSpacer().SpacingAppleStrings([]byte(src))))
+}
+
+func (suite *AppleTestSuite) TestSpacingXCStrings() {
+	src := `{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "Hello %@" : {
+      "localizations" : {
+        "zh-Hant" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "你好%@，歡迎使用App"
+          }
+        }
+      }
+    },
+    "%lld files" : {
+      "comment" : "檔案數量count",
+      "localizations" : {
+        "zh-Hant" : {
+          "variations" : {
+            "plural" : {
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "共%lld個PDF檔案"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "version" : "1.0"
+}`
+	out, err := pangu.NewSpacer().SpacingXCStrings([]byte(src))
+	suite.Nil(err)
+	suite.Contains(string(out), `"value" : "你好 %@，歡迎使用 App"`)
+	suite.Contains(string(out), `"value" : "共 %lld 個 PDF 檔案"`)
+	suite.Contains(string(out), `"comment" : "檔案數量count"`)
+	suite.Contains(string(out), `"Hello %@" : {`)
+}
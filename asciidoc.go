@@ -0,0 +1,127 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// adocDelimiter matches the delimiter lines of AsciiDoc blocks.
+var adocDelimiter = regexp.MustCompile(`^(?:-{4,}|\.{4,}|\+{4,}|/{4,}|={4,}|\*{4,}|_{4,}|--|` + "`{3}" + `[^\s]*|\|={3,})[ \t]*$`)
+
+// adocSkipped matches the lines left alone: comments, attribute entries,
+// block attributes and anchors, and block macros such as include:: or
+// image::.
+var adocSkipped = regexp.MustCompile(`^(?://|:!?[A-Za-z0-9_][A-Za-z0-9_-]*!?:(?:[ \t]|$)|\[.*\][ \t]*$|[A-Za-z0-9_-]+::\S*\[.*\][ \t]*$)`)
+
+// adocInline matches inline literals and passthroughs, attribute
+// references, cross references, inline anchors, inline macros, URLs,
+// backslash escapes and table cell separators.
+var adocInline = regexp.MustCompile("``[^`]+``|`[^`]+`|\\+\\+\\+[^+]+\\+\\+\\+|\\+[^+\\s][^+]*\\+|\\{[A-Za-z0-9_-]+\\}|<<[^<>]+>>|\\[\\[\\[?[^\\[\\]]+\\]\\]\\]?|[A-Za-z0-9_-]+:[^\\s\\[]*\\[[^\\]]*\\]|https?://[^\\s\\[<>]+(?:\\[[^\\]]*\\])?|\\\\[^\\s\\\\A-Za-z0-9]|\\|")
+
+// adocRawBlocks are the delimiters of the blocks whose content is never
+// changed: listings, literals, passthroughs, comments and code fences.
+var adocRawBlocks = map[byte]bool{'-': true, '.': true, '+': true, '/': true, '`': true}
+
+// SpacingAsciiDoc performs paranoid text spacing on an AsciiDoc document,
+// such as an Antora page. Only prose, section titles, list items, table
+// cells and the content of example, sidebar, quote and open blocks are
+// changed. Listing, literal, passthrough and comment blocks, literal
+// paragraphs, inline literals, attribute entries and references, block
+// attributes, anchors, macros and their link targets, URLs, backslash
+// escapes, comments and front matter are left alone, as is everything
+// else, byte for byte, as SpacingMarkdown does.
+//
+// Inline literals and passthroughs are seen by the rules as the text they
+// hold, and other inline markup as a word, so "執行`go test`指令" becomes
+// "執行 `go test` 指令".
+func (s *Spacer) SpacingAsciiDoc(src []byte) []byte {
+	var out strings.Builder
+	fence := ""        // delimiter of the raw block in effect, if any
+	literal := false   // whether inside a literal paragraph
+	paragraph := false // whether inside a paragraph
+	lines := splitLines(string(src))
+	front := frontMatter(lines)
+	for i, line := range lines {
+		if i < front {
+			out.WriteString(line)
+			continue
+		}
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+		trimmed := strings.TrimRight(body, " \t")
+
+		if fence != "" {
+			if trimmed == fence {
+				fence = ""
+			}
+			out.WriteString(line)
+			continue
+		}
+		if trimmed == "" {
+			literal, paragraph = false, false
+			out.WriteString(line)
+			continue
+		}
+		if !paragraph && (body[0] == ' ' || body[0] == '\t') {
+			literal = true
+		}
+		if literal {
+			out.WriteString(line)
+			continue
+		}
+
+		if adocDelimiter.MatchString(trimmed) {
+			if adocRawBlocks[trimmed[0]] && trimmed != "--" {
+				fence = trimmed
+				if trimmed[0] == '`' {
+					fence = "```"
+				}
+			}
+			paragraph = false
+			out.WriteString(line)
+			continue
+		}
+		if adocSkipped.MatchString(body) {
+			out.WriteString(line)
+			continue
+		}
+
+		paragraph = true
+		out.WriteString(s.spacingSegments(adocSegments(body)) + eol)
+	}
+
+	return s.suppressed(src, []byte(out.String()), slashDirective)
+}
+
+// adocSegments splits a line of AsciiDoc prose into segments. The dot
+// of a block title is seen as a line break.
+func adocSegments(line string) []segment {
+	var segs []segment
+	if strings.HasPrefix(line, ".") && len(line) > 1 && line[1] != '.' && line[1] != ' ' {
+		segs = append(segs, opaque(".", "\n"))
+		line = line[1:]
+	}
+
+	return append(segs, splitSegments(line, adocInline, decodeAsciiDoc)...)
+}
+
+// decodeAsciiDoc tells how the rules see a piece of inline markup:
+// inline literals and passthroughs as the text they hold, backslash
+// escapes as the character they escape, table cell separators as line
+// breaks, anything else as a word.
+func decodeAsciiDoc(raw string) string {
+	switch {
+	case raw == "|":
+		return "\n"
+	case strings.HasPrefix(raw, "+++"):
+		return raw[3 : len(raw)-3]
+	case strings.HasPrefix(raw, "``"):
+		return raw[2 : len(raw)-2]
+	case raw[0] == '\\':
+		return raw[1:]
+	case raw[0] == '`', raw[0] == '+':
+		return raw[1 : len(raw)-1]
+	}
+
+	return "0"
+}
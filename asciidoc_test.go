@@ -0,0 +1,95 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type AsciiDocTestSuite struct {
+	suite.Suite
+}
+
+func TestAsciiDocTestSuite(t *testing.T) {
+	suite.Run(t, new(AsciiDocTestSuite))
+}
+
+func (suite *AsciiDocTestSuite) TestSpacingAsciiDoc() {
+	src := "= 文件Title\n" +
+		":toc-title: 目錄Contents\n" +
+		":url-repo: https://example.com/中文abc\n" +
+		"\n" +
+		"// 註解comment\n" +
+		"當你凝視著bug，bug也凝視著你\n" +
+		"執行`go test`指令，見<<intro,簡介>>與{url-repo}[專案Repo]說明\n" +
+		"\n" +
+		"[source,go]\n" +
+		".範例Example\n" +
+		"----\n" +
+		"fmt.Println(\"中文English\")\n" +
+		"----\n" +
+		"\n" +
+		"  literal段落Paragraph\n" +
+		"  還是literal\n" +
+		"\n" +
+		"////\n" +
+		"註解區塊comment\n" +
+		"////\n" +
+		"\n" +
+		"image::圖片Image.png[圖片Image]\n" +
+		"\n" +
+		"|===\n" +
+		"|名稱Name |說明`code`\n" +
+		"|===\n" +
+		"\n" +
+		"* 第1項item\n" +
+		"\n" +
+		"```go\n" +
+		"x := \"中文English\"\n" +
+		"```\n"
+	suite.Equal("= 文件 Title\n"+
+		":toc-title: 目錄Contents\n"+
+		":url-repo: https://example.com/中文abc\n"+
+		"\n"+
+		"// 註解comment\n"+
+		"當你凝視著 bug，bug 也凝視著你\n"+
+		"執行 `go test` 指令，見 <<intro,簡介>> 與 {url-repo}[專案 Repo] 說明\n"+
+		"\n"+
+		"[source,go]\n"+
+		".範例 Example\n"+
+		"----\n"+
+		"fmt.Println(\"中文English\")\n"+
+		"----\n"+
+		"\n"+
+		"  literal段落Paragraph\n"+
+		"  還是literal\n"+
+		"\n"+
+		"////\n"+
+		"註解區塊comment\n"+
+		"////\n"+
+		"\n"+
+		"image::圖片Image.png[圖片Image]\n"+
+		"\n"+
+		"|===\n"+
+		"|名稱 Name |說明 `code`\n"+
+		"|===\n"+
+		"\n"+
+		"* 第 1 項 item\n"+
+		"\n"+
+		"```go\n"+
+		"x := \"中文English\"\n"+
+		"```\n", string(pangu.NewSpacer().SpacingAsciiDoc([]byte(src))))
+}
+
+func (suite *AsciiDocTestSuite) TestProtections() {
+	src := "---\n" +
+		"title: 文件Title\n" +
+		"---\n" +
+		"見[[錨點anchor]]與``go test``及link:https://example.com/中文abc[連結Link]說明\n" +
+		"跳脫\\{escaped}與\\#號\n"
+	suite.Equal("---\n"+
+		"title: 文件Title\n"+
+		"---\n"+
+		"見 [[錨點anchor]] 與 ``go test`` 及 link:https://example.com/中文abc[連結Link] 說明\n"+
+		"跳脫 \\{escaped} 與 \\#號\n", string(pangu.NewSpacer().SpacingAsciiDoc([]byte(src))))
+}
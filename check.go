@@ -0,0 +1,205 @@
+package pangu
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A Problem is a place where paranoid text spacing would change a text,
+// or a suppression directive that did not suppress anything.
+type Problem struct {
+	Line    int // 1-based line number
+	Column  int // 1-based column, counted in characters
+	Message string
+	Rule    string // "missing-space", "extra-space", "wrong-space" or "unused-directive"
+	Fix     *Edit  // the edit fixing the problem, if any
+
+	// The position right after the text replaced by Fix, if any, which
+	// is Line and Column for an insertion.
+	EndLine   int
+	EndColumn int
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// Check reads r, performs paranoid text spacing on its contents like
+// SpacingFile does and reports what would change, without writing
+// anything. Unused suppression directives are reported as well.
+func (s *Spacer) Check(r io.Reader) ([]Problem, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(b)
+
+	return check(text, s.spacingLines(text), anyDirective), nil
+}
+
+// CheckMode reports what a format-aware mode would change in src, the
+// way Check does for plain text, unused directives included. mode is
+// called with a Spacer that has the rules and protections of s but leaves
+// directives alone, so that CheckMode can tell which ones are used; it
+// must only be used to run a mode on src:
+//
+//	problems, err := s.CheckMode(src, func(s *pangu.Spacer, src []byte) ([]byte, error) {
+//		return s.SpacingPO(src), nil
+//	})
+func (s *Spacer) CheckMode(src []byte, mode func(s *Spacer, src []byte) ([]byte, error)) ([]Problem, error) {
+	raw := &Spacer{
+		disabled:      s.disabled,
+		patterns:      s.patterns,
+		dict:          s.dictionary(),
+		rawDirectives: true,
+	}
+	spaced, err := mode(raw, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return check(string(src), string(spaced), raw.directives), nil
+}
+
+// check reports the problems of text, given its spaced version with the
+// directives matched by pattern not yet honored.
+func check(text, spaced string, pattern *regexp.Regexp) []Problem {
+	spaced, sups := suppress(text, spaced, pattern)
+	problems := Problems(text, spaced)
+
+	for _, sup := range sups {
+		if !sup.Used {
+			problems = append(problems, Problem{
+				Line:    sup.Line,
+				Column:  1,
+				Message: "unused " + sup.Directive,
+				Rule:    "unused-directive",
+			})
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	return problems
+}
+
+// CheckFile is like Check but reads the file named by filename.
+func (s *Spacer) CheckFile(filename string) ([]Problem, error) {
+	fr, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+
+	return s.Check(fr)
+}
+
+// An Edit is a change that paranoid text spacing makes to a text: the
+// bytes of the text from Start to End are replaced with New.
+type Edit struct {
+	Start int // byte offset
+	End   int // byte offset
+	New   string
+}
+
+// Message describes e as in Problem: "missing space", "extra space" or
+// "wrong space".
+func (e Edit) Message() string {
+	switch {
+	case e.New == "":
+		return "extra space"
+	case e.End > e.Start:
+		return "wrong space"
+	}
+
+	return "missing space"
+}
+
+// Rule returns the rule id of e as in Problem, such as "missing-space".
+func (e Edit) Rule() string {
+	return strings.Replace(e.Message(), " ", "-", -1)
+}
+
+// Edits compares a text with its spaced version and returns the edits
+// that turn one into the other, sorted and not overlapping, with offsets
+// in bytes of text. Editors can apply them without replacing the whole
+// text.
+func Edits(text, spaced string) []Edit {
+	var edits []Edit
+	for _, e := range diff(text, spaced) {
+		edits = append(edits, Edit{Start: e.start, End: e.end, New: e.ins})
+	}
+
+	return edits
+}
+
+// Problems compares a text with its spaced version and reports every
+// inserted or removed space as a Problem located in text.
+func Problems(text, spaced string) []Problem {
+	var problems []Problem
+	line, col, last := 1, 1, 0
+	for _, e := range Edits(text, spaced) {
+		line, col = advance(text, last, e.Start, line, col)
+		last = e.Start
+		endLine, endCol := advance(text, e.Start, e.End, line, col)
+
+		fix := e
+		problems = append(problems, Problem{
+			Line:      line,
+			Column:    col,
+			Message:   e.Message(),
+			Rule:      e.Rule(),
+			Fix:       &fix,
+			EndLine:   endLine,
+			EndColumn: endCol,
+		})
+	}
+
+	return problems
+}
+
+// advance returns the line and column reached by reading text from start
+// to end, starting at line and col.
+func advance(text string, start, end, line, col int) (int, int) {
+	for i, c := range text[start:end] {
+		// A lone "\r" ends a line too, as in splitLines.
+		if c == '\n' || c == '\r' && !strings.HasPrefix(text[start+i+1:], "\n") {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+
+	return line, col
+}
+
+// splitLines splits text after each line ending, be it "\n", "\r\n" or
+// a lone "\r", keeping the line endings.
+func splitLines(text string) []string {
+	var lines []string
+	for len(text) > 0 {
+		i := strings.IndexAny(text, "\r\n")
+		if i < 0 {
+			lines = append(lines, text)
+			break
+		}
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			i++
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+
+	return lines
+}
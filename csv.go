@@ -0,0 +1,112 @@
+package pangu
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// SpacingCSV performs paranoid text spacing on a CSV document whose
+// fields are separated by comma, such as ',' or '\t' for TSV. The first
+// record is the header, which is never changed; if columns is not empty,
+// only the fields under those headers are changed, otherwise every field
+// is. Quoting, line endings and multi-line quoted fields are kept as they
+// are, byte for byte.
+func (s *Spacer) SpacingCSV(src []byte, comma rune, columns ...string) ([]byte, error) {
+	// encoding/csv only knows "\n" and "\r\n" line endings.
+	if bytes.IndexByte(src, '\n') < 0 && bytes.IndexByte(src, '\r') >= 0 {
+		out, err := s.SpacingCSV(bytes.Replace(src, []byte("\r"), []byte("\n"), -1), comma, columns...)
+		return bytes.Replace(out, []byte("\n"), []byte("\r"), -1), err
+	}
+
+	r := csv.NewReader(bytes.NewReader(src))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	// Offsets of the lines, as reported by FieldPos.
+	lines := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+
+	var selected map[int]bool
+	var out bytes.Buffer
+	last := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if selected == nil {
+			selected = map[int]bool{}
+			for i, name := range record {
+				name = strings.TrimPrefix(name, "\ufeff")
+				selected[i] = len(columns) == 0 || contains(columns, name)
+			}
+			continue
+		}
+
+		for i, field := range record {
+			if !selected[i] || field == "" {
+				continue
+			}
+			line, column := r.FieldPos(i)
+			start := lines[line-1] + column - 1
+			var spaced string
+			end := start + len(field)
+			if src[start] == '"' {
+				end = csvQuoteEnd(src, start)
+				var segs []segment
+				for j, part := range strings.Split(string(src[start+1:end-1]), `""`) {
+					if j > 0 {
+						segs = append(segs, opaque(`""`, `"`))
+					}
+					segs = append(segs, literal(part))
+				}
+				spaced = `"` + s.spacingSegments(segs) + `"`
+			} else {
+				spaced = s.SpacingText(field)
+			}
+			out.Write(src[last:start])
+			out.WriteString(spaced)
+			last = end
+		}
+	}
+	out.Write(src[last:])
+
+	return s.suppressed(src, out.Bytes(), nil), nil
+}
+
+// csvQuoteEnd returns the offset right after the quoted field starting
+// at src[start].
+func csvQuoteEnd(src []byte, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		if src[i] != '"' {
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == '"' {
+			i++
+			continue
+		}
+		return i + 1
+	}
+
+	return len(src)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type CSVTestSuite struct {
+	suite.Suite
+}
+
+func TestCSVTestSuite(t *testing.T) {
+	suite.Run(t, new(CSVTestSuite))
+}
+
+func (suite *CSVTestSuite) TestColumns() {
+	src := "\ufeffsku,title,description\r\n" +
+		"A1,與PM戰鬥的人,\"當你凝視著bug，\r\nbug也凝視著你\"\r\n" +
+		"B2-中文,\"他說\"\"PM\"\"是敵人\",\r\n"
+	out, err := pangu.NewSpacer().SpacingCSV([]byte(src), ',', "title", "description")
+	suite.Nil(err)
+	suite.Equal("\ufeffsku,title,description\r\n"+
+		"A1,與 PM 戰鬥的人,\"當你凝視著 bug，\r\nbug 也凝視著你\"\r\n"+
+		"B2-中文,\"他說 \"\"PM\"\" 是敵人\",\r\n", string(out))
+}
+
+func (suite *CSVTestSuite) TestAllColumns() {
+	src := "名稱Name\t說明\n" +
+		"蘋果Apple\t很好吃yummy\n"
+	out, err := pangu.NewSpacer().SpacingCSV([]byte(src), '\t')
+	suite.Nil(err)
+	suite.Equal("名稱Name\t說明\n"+
+		"蘋果 Apple\t很好吃 yummy\n", string(out))
+}
+
+func (suite *CSVTestSuite) TestInvalid() {
+	_, err := pangu.NewSpacer().SpacingCSV([]byte("a,b\n\"中文,x\n"), ',')
+	suite.NotNil(err)
+}
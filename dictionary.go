@@ -0,0 +1,152 @@
+package pangu
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Protect adds terms to the dictionary of s. Every occurrence of a term
+// is left exactly as it is, though spacing may still be inserted right
+// before or after it. A term starting or ending with an ASCII letter or
+// digit only matches as a whole word, so "3D打印" is protected in
+// "用3D打印機" but not in "13D打印".
+//
+// Terms are matched with an Aho-Corasick automaton, so large
+// dictionaries cost little more than small ones.
+func (s *Spacer) Protect(terms ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, term := range terms {
+		if term != "" {
+			s.terms = append(s.terms, term)
+		}
+	}
+	s.dict = nil
+}
+
+// ReadDictionary reads terms from r, one per line. Leading and trailing
+// whitespace is ignored, as are blank lines and lines starting with "#".
+func ReadDictionary(r io.Reader) ([]string, error) {
+	var terms []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		term := strings.TrimSpace(scanner.Text())
+		if term == "" || strings.HasPrefix(term, "#") {
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, scanner.Err()
+}
+
+// dictionary returns the automaton of s, building it if needed.
+func (s *Spacer) dictionary() *automaton {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dict == nil && len(s.terms) > 0 {
+		s.dict = newAutomaton(s.terms)
+	}
+
+	return s.dict
+}
+
+// automaton is an Aho-Corasick automaton over the bytes of the terms.
+type automaton struct {
+	next []map[byte]int32
+	fail []int32
+	out  [][]int // lengths of the terms ending at each state
+}
+
+func newAutomaton(terms []string) *automaton {
+	a := &automaton{}
+	a.add()
+
+	for _, term := range terms {
+		state := int32(0)
+		for i := 0; i < len(term); i++ {
+			next, ok := a.next[state][term[i]]
+			if !ok {
+				next = a.add()
+				a.next[state][term[i]] = next
+			}
+			state = next
+		}
+		a.out[state] = append(a.out[state], len(term))
+	}
+
+	// Breadth-first, so the failure state of a parent is always known
+	// before the ones of its children.
+	queue := []int32{}
+	for _, child := range a.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, child := range a.next[state] {
+			queue = append(queue, child)
+
+			fail := a.fail[state]
+			for {
+				if next, ok := a.next[fail][b]; ok {
+					a.fail[child] = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = a.fail[fail]
+			}
+			a.out[child] = append(a.out[child], a.out[a.fail[child]]...)
+		}
+	}
+
+	return a
+}
+
+func (a *automaton) add() int32 {
+	a.next = append(a.next, map[byte]int32{})
+	a.fail = append(a.fail, 0)
+	a.out = append(a.out, nil)
+
+	return int32(len(a.next) - 1)
+}
+
+// find returns the byte ranges of every whole-term match in text.
+func (a *automaton) find(text string) []span {
+	var spans []span
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		for {
+			if next, ok := a.next[state][text[i]]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = a.fail[state]
+		}
+
+		for _, n := range a.out[state] {
+			start, end := i+1-n, i+1
+			if start > 0 && isAlnum(text[start]) && isAlnum(text[start-1]) {
+				continue
+			}
+			if end < len(text) && isAlnum(text[end-1]) && isAlnum(text[end]) {
+				continue
+			}
+			spans = append(spans, span{start, end})
+		}
+	}
+
+	return spans
+}
+
+func isAlnum(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
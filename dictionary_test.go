@@ -0,0 +1,56 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"strings"
+	"testing"
+)
+
+type DictionaryTestSuite struct {
+	suite.Suite
+}
+
+func TestDictionaryTestSuite(t *testing.T) {
+	suite.Run(t, new(DictionaryTestSuite))
+}
+
+func (suite *DictionaryTestSuite) TestProtect() {
+	s := pangu.NewSpacer()
+	s.Protect("3D打印", "iPhone手機殼")
+
+	suite.Equal(`用 3D打印機`, s.SpacingText(`用3D打印機`))
+	suite.Equal(`買了 iPhone手機殼和 3D打印機`, s.SpacingText(`買了iPhone手機殼和3D打印機`))
+	suite.Equal(`新八的構造成分有 95% 是眼鏡`, s.SpacingText(`新八的構造成分有95%是眼鏡`))
+}
+
+func (suite *DictionaryTestSuite) TestWholeTerm() {
+	s := pangu.NewSpacer()
+	s.Protect("3D打印")
+
+	suite.Equal(`用 13D 打印機`, s.SpacingText(`用13D打印機`))
+}
+
+func (suite *DictionaryTestSuite) TestOverlappingTerms() {
+	s := pangu.NewSpacer()
+	s.Protect("he", "she", "his", "hers", "D打印", "3D打")
+
+	suite.Equal(`用 3D打印機`, s.SpacingText(`用3D打印機`))
+	suite.Equal(`她是 she`, s.SpacingText(`她是she`))
+}
+
+func (suite *DictionaryTestSuite) TestReadDictionary() {
+	terms, err := pangu.ReadDictionary(strings.NewReader("# brands\n3D打印\n\n  iPhone手機殼  \n"))
+	suite.Nil(err)
+	suite.Equal([]string{"3D打印", "iPhone手機殼"}, terms)
+}
+
+func (suite *DictionaryTestSuite) TestLargeDictionary() {
+	s := pangu.NewSpacer()
+	for i := 0; i < 10000; i++ {
+		s.Protect(strings.Repeat("字", i%7+1) + "X" + string(rune('a'+i%26)))
+	}
+	s.Protect("3D打印")
+
+	suite.Equal(`用 3D打印機`, s.SpacingText(`用3D打印機`))
+}
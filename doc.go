@@ -12,6 +12,10 @@
 // heritage to their cat. Indeed, love and writing need some space in
 // good time.
 //
+// SpacingFile, SpacingDocument and the format modes, such as SpacingYAML,
+// only ever insert or remove spaces: byte order marks, "\n", "\r\n" or
+// "\r" line endings and a missing final newline are kept as they are.
+//
 // For more information about pangu, see
 // 	https://github.com/vinta/paranoid-auto-spacing
 package pangu
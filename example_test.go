@@ -25,3 +25,11 @@ func ExampleSpacingFile() {
 
 	pangu.SpacingFile(input, fw)
 }
+
+func ExampleSpacer_Protect() {
+	s := pangu.NewSpacer()
+	s.Protect("3D打印", "iPhone手機殼")
+	fmt.Println(s.SpacingText("用3D打印機做iPhone手機殼"))
+	// Output:
+	// 用 3D打印機做 iPhone手機殼
+}
@@ -0,0 +1,175 @@
+package pangu
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// goDirective matches comments that are read by tools, such as //go:build
+// or //line, which must never change.
+var goDirective = regexp.MustCompile(`^//(line |extern |export |[a-z0-9]+:[a-z0-9]| \+build)`)
+
+// SpacingGo performs paranoid text spacing on Go source code. Only the
+// text of comments and string literals is changed; identifiers, code,
+// import paths, struct tags and directives such as //go:build are left
+// alone. gofmt-formatted source stays gofmt-formatted: the alignment of
+// the lines around comments and strings that grow is fixed up with
+// go/format.
+//
+// If funcs is not empty, only the string literals passed as arguments to
+// those functions are changed, while comments are changed as usual.
+// Functions are named either as they are called, like "i18n.T", or by
+// their bare name, like "T".
+func (s *Spacer) SpacingGo(src []byte, funcs ...string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset
+	}
+
+	var targets []span
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			start := offset(c.Pos())
+			targets = append(targets, span{start, start + goTokenLen(src[start:])})
+		}
+	}
+
+	skip := map[*ast.BasicLit]bool{}
+	for _, imp := range f.Imports {
+		skip[imp.Path] = true
+	}
+	var strs []*ast.BasicLit
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			if n.Tag != nil {
+				skip[n.Tag] = true
+			}
+		case *ast.BasicLit:
+			if n.Kind == token.STRING && len(funcs) == 0 {
+				strs = append(strs, n)
+			}
+		case *ast.CallExpr:
+			if len(funcs) > 0 && calls(n, funcs) {
+				for _, arg := range n.Args {
+					if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						strs = append(strs, lit)
+					}
+				}
+			}
+		}
+		return true
+	})
+	for _, lit := range strs {
+		if !skip[lit] {
+			start := offset(lit.Pos())
+			targets = append(targets, span{start, start + goTokenLen(src[start:])})
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].start < targets[j].start
+	})
+
+	var buf bytes.Buffer
+	last := 0
+	for _, t := range targets {
+		buf.Write(src[last:t.start])
+		buf.WriteString(s.spacingGoToken(string(src[t.start:t.end])))
+		last = t.end
+	}
+	buf.Write(src[last:])
+
+	out := s.suppressed(src, buf.Bytes(), cDirective)
+	if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
+	}
+
+	return out, nil
+}
+
+// calls reports whether call is a call to one of funcs.
+func calls(call *ast.CallExpr, funcs []string) bool {
+	var name, full string
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		name, full = fn.Name, fn.Name
+	case *ast.SelectorExpr:
+		name = fn.Sel.Name
+		if x, ok := fn.X.(*ast.Ident); ok {
+			full = x.Name + "." + name
+		}
+	default:
+		return false
+	}
+
+	for _, f := range funcs {
+		if f == name || f == full {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goTokenLen returns the length of the comment or string literal at the
+// start of src, measured in src itself since go/scanner drops carriage
+// returns from the values it reports.
+func goTokenLen(src []byte) int {
+	switch {
+	case bytes.HasPrefix(src, []byte("//")):
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			return i
+		}
+		return len(src)
+	case bytes.HasPrefix(src, []byte("/*")):
+		return bytes.Index(src, []byte("*/")) + 2
+	case src[0] == '`':
+		return bytes.IndexByte(src[1:], '`') + 2
+	}
+
+	// An interpreted string literal, which cannot span lines.
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return len(src)
+}
+
+// spacingGoToken performs spacing on the text of a comment or a string
+// literal, leaving its delimiters and escape sequences alone.
+func (s *Spacer) spacingGoToken(tok string) string {
+	switch {
+	case strings.HasPrefix(tok, "//"):
+		if goDirective.MatchString(tok) {
+			return tok
+		}
+		return "//" + s.SpacingText(tok[2:])
+	case strings.HasPrefix(tok, "/*"):
+		return "/*" + s.SpacingText(tok[2:len(tok)-2]) + "*/"
+	case strings.HasPrefix(tok, "`"):
+		return "`" + s.SpacingText(tok[1:len(tok)-1]) + "`"
+	}
+
+	segs := escapedSegments(tok[1:len(tok)-1], nil)
+
+	return `"` + s.spacingSegments(segs) + `"`
+}
@@ -0,0 +1,85 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"go/format"
+	"testing"
+)
+
+type GoTestSuite struct {
+	suite.Suite
+}
+
+func TestGoTestSuite(t *testing.T) {
+	suite.Run(t, new(GoTestSuite))
+}
+
+const goSource = "//go:generate echo 中文abc\n" +
+	"\n" +
+	"// Package 範例demo示範pangu.\n" +
+	"package demo\n" +
+	"\n" +
+	"import \"fmt\"\n" +
+	"\n" +
+	"type 資料Data struct {\n" +
+	"\tName string `json:\"名字name\"`\n" +
+	"}\n" +
+	"\n" +
+	"/* 區塊block註解 */\n" +
+	"func Hello(名字abc string) {\n" +
+	"\tfmt.Println(\"你好\\n\" + 名字abc + \"!\")\n" +
+	"\tfmt.Println(T(\"與PM戰鬥的人\\t\\u4e2dAPI\"), `當你凝視著bug`)\n" +
+	"}\n"
+
+func (suite *GoTestSuite) TestSpacingGo() {
+	out, err := pangu.NewSpacer().SpacingGo([]byte(goSource))
+	suite.Nil(err)
+	suite.Equal("//go:generate echo 中文abc\n"+
+		"\n"+
+		"// Package 範例 demo 示範 pangu.\n"+
+		"package demo\n"+
+		"\n"+
+		"import \"fmt\"\n"+
+		"\n"+
+		"type 資料Data struct {\n"+
+		"\tName string `json:\"名字name\"`\n"+
+		"}\n"+
+		"\n"+
+		"/* 區塊 block 註解 */\n"+
+		"func Hello(名字abc string) {\n"+
+		"\tfmt.Println(\"你好\\n\" + 名字abc + \"!\")\n"+
+		"\tfmt.Println(T(\"與 PM 戰鬥的人\\t\\u4e2d API\"), `當你凝視著 bug`)\n"+
+		"}\n", string(out))
+}
+
+func (suite *GoTestSuite) TestSpacingGoFuncs() {
+	out, err := pangu.NewSpacer().SpacingGo([]byte(goSource), "T")
+	suite.Nil(err)
+	suite.Contains(string(out), "// Package 範例 demo 示範 pangu.\n")
+	suite.Contains(string(out), "T(\"與 PM 戰鬥的人\\t\\u4e2d API\"), `當你凝視著bug`)")
+}
+
+func (suite *GoTestSuite) TestSpacingGoSyntaxError() {
+	_, err := pangu.NewSpacer().SpacingGo([]byte("package"))
+	suite.Error(err)
+}
+
+func (suite *GoTestSuite) TestGofmt() {
+	src := "package demo\n" +
+		"\n" +
+		"var m = map[string]string{\n" +
+		"\t\"中文a\":  \"值value\", // 註解a\n" +
+		"\t\"bcde\": \"b\",      // 註解b\n" +
+		"}\n"
+	formatted, err := format.Source([]byte(src))
+	suite.Require().Nil(err)
+	suite.Require().Equal(src, string(formatted))
+
+	out, err := pangu.NewSpacer().SpacingGo([]byte(src))
+	suite.Nil(err)
+	suite.Contains(string(out), "\"中文 a\": \"值 value\", // 註解 a\n")
+	formatted, err = format.Source(out)
+	suite.Nil(err)
+	suite.Equal(string(formatted), string(out))
+}
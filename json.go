@@ -0,0 +1,275 @@
+package pangu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SpacingJSON performs paranoid text spacing on a JSON document, such as
+// an i18n resource bundle. Only string values are changed; keys, other
+// values, key order and formatting are left exactly as they are, as are
+// escape sequences and the structure of ICU MessageFormat patterns such
+// as {count, plural, one {# file} other {# files}}.
+//
+// If paths is not empty, only the values whose key path matches one of
+// the patterns are changed. A key path joins the object keys and array
+// indexes leading to a value with dots, like "menu.items.0.title"; in a
+// pattern, "*" matches a single key and "**" any number of keys.
+func (s *Spacer) SpacingJSON(src []byte, paths ...string) ([]byte, error) {
+	out, err := s.spacingJSON(src, icuSegments, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.suppressed(src, out, nil), nil
+}
+
+// spacingJSON spaces the string values at paths, splitting their raw
+// content into segments with split.
+func (s *Spacer) spacingJSON(src []byte, split func(string) []segment, paths []string) ([]byte, error) {
+	// A byte order mark is not JSON, but Windows editors like to write one.
+	bom := bytes.HasPrefix(src, []byte("\ufeff"))
+	if bom {
+		src = src[3:]
+	}
+	if !json.Valid(src) {
+		return nil, errors.New("pangu: invalid JSON")
+	}
+
+	w := &jsonWalker{src: src, spacer: s, split: split, paths: paths}
+	if bom {
+		w.out.WriteString("\ufeff")
+	}
+	w.value(nil)
+	w.out.Write(src[w.last:])
+
+	return w.out.Bytes(), nil
+}
+
+// jsonWalker walks a valid JSON document, copying it to out with its
+// string values spaced.
+type jsonWalker struct {
+	src    []byte
+	pos    int
+	last   int // end of the part of src already copied to out
+	out    bytes.Buffer
+	spacer *Spacer
+	split  func(string) []segment
+	paths  []string
+}
+
+func (w *jsonWalker) skipSpace() {
+	for w.pos < len(w.src) && isSpace(w.src[w.pos]) {
+		w.pos++
+	}
+}
+
+func (w *jsonWalker) value(keys []string) {
+	w.skipSpace()
+	switch w.src[w.pos] {
+	case '{':
+		w.pos++
+		for {
+			w.skipSpace()
+			if w.src[w.pos] == '}' {
+				w.pos++
+				return
+			}
+			if w.src[w.pos] == ',' {
+				w.pos++
+				w.skipSpace()
+			}
+			start := w.pos
+			w.str()
+			key, _ := strconv.Unquote(string(w.src[start:w.pos]))
+			w.skipSpace()
+			w.pos++ // the colon
+			w.value(append(keys, key))
+		}
+	case '[':
+		w.pos++
+		for i := 0; ; i++ {
+			w.skipSpace()
+			if w.src[w.pos] == ']' {
+				w.pos++
+				return
+			}
+			if w.src[w.pos] == ',' {
+				w.pos++
+			}
+			w.value(append(keys, strconv.Itoa(i)))
+		}
+	case '"':
+		start := w.pos
+		w.str()
+		if w.match(keys) {
+			w.out.Write(w.src[w.last : start+1])
+			w.out.WriteString(w.spacer.spacingSegments(w.split(string(w.src[start+1 : w.pos-1]))))
+			w.last = w.pos - 1
+		}
+	default:
+		// A number, true, false or null.
+		for w.pos < len(w.src) && !strings.ContainsRune(",]} \t\r\n", rune(w.src[w.pos])) {
+			w.pos++
+		}
+	}
+}
+
+// str skips the string at pos.
+func (w *jsonWalker) str() {
+	w.pos++
+	for w.src[w.pos] != '"' {
+		if w.src[w.pos] == '\\' {
+			w.pos++
+		}
+		w.pos++
+	}
+	w.pos++
+}
+
+func (w *jsonWalker) match(keys []string) bool {
+	if len(w.paths) == 0 {
+		return true
+	}
+	for _, p := range w.paths {
+		if matchKeys(strings.Split(p, "."), keys) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchKeys reports whether keys match the pattern segments, where "**"
+// matches any number of keys.
+func matchKeys(pattern, keys []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(keys); i++ {
+				if matchKeys(pattern[1:], keys[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(keys) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], keys[0]); !ok {
+			return false
+		}
+		pattern, keys = pattern[1:], keys[1:]
+	}
+
+	return len(keys) == 0
+}
+
+// icuSegments splits an ICU MessageFormat pattern into segments. Simple
+// arguments like {name} are opaque but seen as they are; the syntax of
+// plural and select arguments is opaque and seen as a space, so only the
+// text of their sub-messages is spaced. Patterns that do not parse are
+// treated as plain text.
+func icuSegments(msg string) []segment {
+	p := &icuParser{msg: msg}
+	if !p.message(0, false) || p.pos != len(msg) {
+		return escapedSegments(msg, nil)
+	}
+
+	return p.segs
+}
+
+type icuParser struct {
+	msg  string
+	pos  int
+	segs []segment
+}
+
+// message parses text up to an unmatched "}" or the end of msg. In the
+// sub-messages of plural arguments, "#" stands for the number.
+func (p *icuParser) message(depth int, plural bool) bool {
+	start := p.pos
+	flush := func() {
+		p.segs = append(p.segs, escapedSegments(p.msg[start:p.pos], nil)...)
+	}
+	for p.pos < len(p.msg) {
+		switch p.msg[p.pos] {
+		case '}':
+			if depth == 0 {
+				return false
+			}
+			flush()
+			return true
+		case '{':
+			flush()
+			if !p.argument(depth) {
+				return false
+			}
+			start = p.pos
+		case '#':
+			if !plural {
+				p.pos++
+				continue
+			}
+			flush()
+			p.segs = append(p.segs, opaque("#", "0"))
+			p.pos++
+			start = p.pos
+		default:
+			p.pos++
+		}
+	}
+	flush()
+
+	return depth == 0
+}
+
+// argument parses an argument starting with "{" at pos.
+func (p *icuParser) argument(depth int) bool {
+	start := p.pos
+	end := strings.IndexAny(p.msg[start+1:], "{}")
+	if end < 0 {
+		return false
+	}
+	end += start + 1
+
+	fields := strings.Split(p.msg[start+1:end], ",")
+	kind := ""
+	if len(fields) >= 3 {
+		kind = strings.TrimSpace(fields[1])
+	}
+	if p.msg[end] == '}' {
+		if kind != "plural" && kind != "select" && kind != "selectordinal" {
+			// A simple argument such as {name} or {n, number}.
+			p.pos = end + 1
+			raw := p.msg[start:p.pos]
+			p.segs = append(p.segs, opaque(raw, raw))
+			return true
+		}
+	}
+	if len(fields) < 3 {
+		return false
+	}
+
+	// A plural or select argument: the selectors are opaque, and each
+	// sub-message between braces is a message of its own.
+	for {
+		p.segs = append(p.segs, opaque(p.msg[start:end+1], " "))
+		p.pos = end + 1
+		if p.msg[end] == '}' {
+			return true
+		}
+		if !p.message(depth+1, kind != "select") {
+			return false
+		}
+		start = p.pos
+		next := strings.IndexAny(p.msg[start+1:], "{}")
+		if next < 0 {
+			return false
+		}
+		end = start + 1 + next
+	}
+}
@@ -0,0 +1,62 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type JSONTestSuite struct {
+	suite.Suite
+}
+
+func TestJSONTestSuite(t *testing.T) {
+	suite.Run(t, new(JSONTestSuite))
+}
+
+const jsonSource = `{
+  "title中文": "與PM戰鬥的人",
+  "menu": {
+    "open":   "開啟{name}檔案",
+    "files": "{count, plural, =0 {沒有檔案} one {#個PDF檔案} other {共#個檔案}}",
+    "count": 3,
+    "ok": true
+  },
+  "list": ["當你凝視著bug", null, "bug也凝視著你\n中API"]
+}
+`
+
+func (suite *JSONTestSuite) TestSpacingJSON() {
+	out, err := pangu.NewSpacer().SpacingJSON([]byte(jsonSource))
+	suite.Nil(err)
+	suite.Equal(`{
+  "title中文": "與 PM 戰鬥的人",
+  "menu": {
+    "open":   "開啟 {name} 檔案",
+    "files": "{count, plural, =0 {沒有檔案} one {# 個 PDF 檔案} other {共 # 個檔案}}",
+    "count": 3,
+    "ok": true
+  },
+  "list": ["當你凝視著 bug", null, "bug 也凝視著你\n中 API"]
+}
+`, string(out))
+}
+
+func (suite *JSONTestSuite) TestPaths() {
+	out, err := pangu.NewSpacer().SpacingJSON([]byte(jsonSource), "menu.*", "list.0")
+	suite.Nil(err)
+	suite.Contains(string(out), `"與PM戰鬥的人"`)
+	suite.Contains(string(out), `"開啟 {name} 檔案"`)
+	suite.Contains(string(out), `"當你凝視著 bug"`)
+	suite.Contains(string(out), `"bug也凝視著你\n中API"`)
+
+	out, err = pangu.NewSpacer().SpacingJSON([]byte(jsonSource), "**.open")
+	suite.Nil(err)
+	suite.Contains(string(out), `"開啟 {name} 檔案"`)
+	suite.Contains(string(out), `"當你凝視著bug"`)
+}
+
+func (suite *JSONTestSuite) TestInvalid() {
+	_, err := pangu.NewSpacer().SpacingJSON([]byte(`{"a": }`))
+	suite.Error(err)
+}
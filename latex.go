@@ -0,0 +1,316 @@
+package pangu
+
+import (
+	"strings"
+)
+
+// latexTextCommands are the commands whose last argument is text, spaced
+// along with the text around it.
+var latexTextCommands = map[string]bool{
+	"author": true, "caption": true, "chapter": true, "emph": true,
+	"footnote": true, "mbox": true, "paragraph": true, "part": true,
+	"section": true, "subparagraph": true, "subsection": true,
+	"subsubsection": true, "textbf": true, "textit": true, "textmd": true,
+	"textrm": true, "textsc": true, "textsf": true, "textsl": true,
+	"textup": true, "title": true, "underline": true, "uline": true,
+}
+
+// latexBreakCommands are the commands that break the flow of text.
+var latexBreakCommands = map[string]bool{
+	"clearpage": true, "item": true, "linebreak": true, "maketitle": true,
+	"newline": true, "newpage": true, "noindent": true, "par": true,
+	"tableofcontents": true,
+}
+
+// latexReferenceCommands are the commands typeset as a number or a
+// label, which the rules see as a word, so "見圖\ref{fig}" becomes
+// "見圖 \ref{fig}".
+var latexReferenceCommands = map[string]bool{
+	"autoref": true, "cite": true, "Cref": true, "cref": true,
+	"eqref": true, "pageref": true, "ref": true,
+}
+
+// latexRawEnvironments are the environments whose content is never
+// changed: math, verbatim and code listings.
+var latexRawEnvironments = map[string]bool{
+	"align": true, "align*": true, "alignat": true, "alignat*": true,
+	"comment": true, "displaymath": true, "eqnarray": true,
+	"eqnarray*": true, "equation": true, "equation*": true,
+	"flalign": true, "flalign*": true, "gather": true, "gather*": true,
+	"lstlisting": true, "math": true, "minted": true, "multline": true,
+	"multline*": true, "tikzpicture": true, "verbatim": true,
+	"verbatim*": true, "Verbatim": true,
+}
+
+// SpacingLaTeX performs paranoid text spacing on a LaTeX document, such
+// as a thesis written with xeCJK or ctex. Ordinary text and the text
+// arguments of commands like \textbf, \emph or \section are spaced, with
+// the context running across them, so "\textbf{中文}English" becomes
+// "\textbf{中文} English". Commands and their other arguments, math,
+// verbatim and lstlisting environments, \verb and comments are left
+// alone, as is everything else, byte for byte.
+//
+// Inline math and references such as \ref or \cite are seen as a word by
+// the rules, while display math, environments and commands like \item
+// or \\ are seen as line breaks.
+func (s *Spacer) SpacingLaTeX(src []byte) []byte {
+	p := &latexParser{src: string(src)}
+	for {
+		p.text()
+		if p.pos >= len(p.src) {
+			break
+		}
+		// An unbalanced closing brace.
+		p.pos++
+		p.add(p.pos-1, "")
+	}
+
+	return s.suppressed(src, []byte(s.spacingSegments(p.segs)), texDirective)
+}
+
+type latexParser struct {
+	src  string
+	pos  int
+	segs []segment
+}
+
+// add adds src[start:pos] as an opaque segment seen as text.
+func (p *latexParser) add(start int, text string) {
+	p.segs = append(p.segs, opaque(p.src[start:p.pos], text))
+}
+
+// text parses text up to an unbalanced closing brace or the end.
+func (p *latexParser) text() {
+	start := p.pos
+	flush := func() {
+		if p.pos > start {
+			p.segs = append(p.segs, literal(p.src[start:p.pos]))
+		}
+	}
+
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '}':
+			flush()
+			return
+		case '{':
+			flush()
+			p.pos++
+			p.segs = append(p.segs, segment{raw: "{", open: true})
+			p.group()
+		case '%':
+			flush()
+			begin := p.pos
+			if end := strings.IndexAny(p.src[p.pos:], "\r\n"); end >= 0 {
+				p.pos += end
+			} else {
+				p.pos = len(p.src)
+			}
+			p.add(begin, "\n")
+		case '$':
+			flush()
+			p.math()
+		case '~':
+			flush()
+			p.pos++
+			p.add(p.pos-1, " ")
+		case '\\':
+			flush()
+			p.command()
+		default:
+			p.pos++
+			continue
+		}
+		start = p.pos
+	}
+	flush()
+}
+
+// group parses the rest of a group whose opening brace was just read.
+func (p *latexParser) group() {
+	p.text()
+	if p.pos < len(p.src) {
+		p.pos++
+		p.add(p.pos-1, "")
+	}
+}
+
+// math parses inline math, seen as a word, or display math.
+func (p *latexParser) math() {
+	start := p.pos
+	delim := "$"
+	text := "0"
+	if strings.HasPrefix(p.src[p.pos:], "$$") {
+		delim, text = "$$", "\n"
+	}
+	p.pos += len(delim)
+	p.skipTo(delim)
+	p.add(start, text)
+}
+
+// skipTo moves past the next delim that is not escaped, or to the end.
+func (p *latexParser) skipTo(delim string) {
+	for p.pos < len(p.src) {
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			p.pos += len(delim)
+			return
+		}
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos = len(p.src)
+}
+
+// command parses a command and, depending on it, its arguments.
+func (p *latexParser) command() {
+	start := p.pos
+	p.pos++
+	if p.pos >= len(p.src) {
+		p.add(start, "")
+		return
+	}
+
+	if c := p.src[p.pos]; !isLetter(c) {
+		p.pos++
+		switch c {
+		case '(':
+			p.skipTo(`\)`)
+			p.add(start, "0")
+		case '[':
+			p.skipTo(`\]`)
+			p.add(start, "\n")
+		case '\\':
+			p.skipArgs()
+			p.add(start, "\n")
+		case ' ', ',', ';', ':', '!':
+			p.add(start, " ")
+		case '%', '&', '$', '#', '_', '{', '}':
+			p.add(start, string(c))
+		default:
+			p.add(start, "")
+		}
+		return
+	}
+
+	for p.pos < len(p.src) && isLetter(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[start+1 : p.pos]
+	if p.pos < len(p.src) && p.src[p.pos] == '*' {
+		p.pos++
+	}
+
+	switch {
+	case name == "begin":
+		env := p.arg()
+		if latexRawEnvironments[env] {
+			end := `\end{` + env + `}`
+			if i := strings.Index(p.src[p.pos:], end); i >= 0 {
+				p.pos += i + len(end)
+			} else {
+				p.pos = len(p.src)
+			}
+		} else {
+			p.skipArgs()
+		}
+		p.add(start, "\n")
+	case name == "end":
+		p.arg()
+		p.add(start, "\n")
+	case name == "verb", name == "lstinline":
+		for p.pos < len(p.src) && p.src[p.pos] == '[' {
+			p.skip('[', ']')
+		}
+		begin := p.pos
+		if p.pos < len(p.src) {
+			delim := p.src[p.pos : p.pos+1]
+			if delim == "{" {
+				p.skip('{', '}')
+			} else {
+				p.pos++
+				if end := strings.Index(p.src[p.pos:], delim); end >= 0 {
+					p.pos += end + 1
+				} else {
+					p.pos = len(p.src)
+				}
+			}
+		}
+		text := p.src[begin:p.pos]
+		if len(text) >= 2 {
+			text = text[1 : len(text)-1]
+		}
+		p.add(start, text)
+	case latexTextCommands[name]:
+		for p.pos < len(p.src) && p.src[p.pos] == '[' {
+			p.skip('[', ']')
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+			p.add(start, "")
+			return
+		}
+		p.pos++
+		p.segs = append(p.segs, segment{raw: p.src[start:p.pos], open: true})
+		p.group()
+	case latexBreakCommands[name]:
+		p.skipArgs()
+		p.add(start, "\n")
+	case latexReferenceCommands[name]:
+		p.skipArgs()
+		p.add(start, "0")
+	default:
+		p.skipArgs()
+		p.add(start, "")
+	}
+}
+
+// arg moves past a braced argument and returns its content.
+func (p *latexParser) arg() string {
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return ""
+	}
+	start := p.pos
+	p.skip('{', '}')
+
+	return strings.TrimSuffix(p.src[start+1:p.pos], "}")
+}
+
+// skipArgs moves past the arguments right after a command.
+func (p *latexParser) skipArgs() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '{':
+			p.skip('{', '}')
+		case '[':
+			p.skip('[', ']')
+		default:
+			return
+		}
+	}
+}
+
+// skip moves past the balanced group opened at pos.
+func (p *latexParser) skip(open, close byte) {
+	depth := 0
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				p.pos++
+				return
+			}
+		}
+		p.pos++
+	}
+	p.pos = len(p.src)
+}
+
+func isLetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
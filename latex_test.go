@@ -0,0 +1,61 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type LaTeXTestSuite struct {
+	suite.Suite
+}
+
+func TestLaTeXTestSuite(t *testing.T) {
+	suite.Run(t, new(LaTeXTestSuite))
+}
+
+func (suite *LaTeXTestSuite) spacing(src string) string {
+	return string(pangu.NewSpacer().SpacingLaTeX([]byte(src)))
+}
+
+func (suite *LaTeXTestSuite) TestText() {
+	suite.Equal(`當你凝視著 \textbf{bug}，bug 也凝視著你`, suite.spacing(`當你凝視著\textbf{bug}，bug也凝視著你`))
+	suite.Equal(`\textbf{中文} English`, suite.spacing(`\textbf{中文}English`))
+	suite.Equal(`\section{第 1 章 Chapter}\label{sec:第1章}`, suite.spacing(`\section{第1章Chapter}\label{sec:第1章}`))
+	suite.Equal(`見圖 \ref{fig:a} 與文獻 \cite[p.~1]{knuth}`, suite.spacing(`見圖\ref{fig:a}與文獻\cite[p.~1]{knuth}`))
+	suite.Equal(`與 PM 戰鬥的人~AT\&T`, suite.spacing(`與PM戰鬥的人~AT\&T`))
+}
+
+func (suite *LaTeXTestSuite) TestMath() {
+	suite.Equal(`共 $x+1$ 個`, suite.spacing(`共$x+1$個`))
+	suite.Equal(`共 \(n-1\) 個，價格 \$5`, suite.spacing(`共\(n-1\)個，價格\$5`))
+	suite.Equal("公式\n$$a+b=c$$\n中文", suite.spacing("公式\n$$a+b=c$$\n中文"))
+	suite.Equal("\\begin{equation}\n\\text{中文}x+1\n\\end{equation}", suite.spacing("\\begin{equation}\n\\text{中文}x+1\n\\end{equation}"))
+}
+
+func (suite *LaTeXTestSuite) TestRaw() {
+	src := "\\documentclass[UTF8]{ctexart}\n" +
+		"\\usepackage{xeCJK}\n" +
+		"% 註解comment\n" +
+		"\\begin{document}\n" +
+		"使用\\verb|a+b|計算\n" +
+		"\\begin{lstlisting}[language=Go]\n" +
+		"fmt.Println(\"中文English\")\n" +
+		"\\end{lstlisting}\n" +
+		"\\begin{itemize}\n" +
+		"\\item 第1項item\n" +
+		"\\end{itemize}\n" +
+		"\\end{document}\n"
+	suite.Equal("\\documentclass[UTF8]{ctexart}\n"+
+		"\\usepackage{xeCJK}\n"+
+		"% 註解comment\n"+
+		"\\begin{document}\n"+
+		"使用 \\verb|a+b| 計算\n"+
+		"\\begin{lstlisting}[language=Go]\n"+
+		"fmt.Println(\"中文English\")\n"+
+		"\\end{lstlisting}\n"+
+		"\\begin{itemize}\n"+
+		"\\item 第 1 項 item\n"+
+		"\\end{itemize}\n"+
+		"\\end{document}\n", suite.spacing(src))
+}
@@ -0,0 +1,141 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mdFence matches the opening of a fenced code block.
+var mdFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// mdListItem matches the marker of a list item.
+var mdListItem = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])(?:[ \t]|$)`)
+
+// mdSkipped matches the lines left alone: link reference definitions,
+// setext heading underlines, thematic breaks and HTML blocks.
+var mdSkipped = regexp.MustCompile(`^ {0,3}(?:\[[^\]]+\]:|(?:=+|-+)[ \t]*$|(?:[-*_][ \t]*){3,}$|</?[A-Za-z][A-Za-z0-9-]*(?:[ \t>/]|$)|<!--)`)
+
+// mdInline matches code spans, autolinks, inline HTML, images, the
+// syntax of links, URLs, backslash escapes, emphasis delimiters and
+// table cell separators.
+var mdInline = regexp.MustCompile("(`+)[^`]+?`+|<(?:https?|mailto):[^<>\\s]+>|</?[A-Za-z][A-Za-z0-9-]*(?:\\s[^<>]*)?/?>|!\\[[^\\]]*\\]\\([^)]*\\)|\\]\\([^)]*\\)|\\]\\[[^\\]]*\\]|\\[|https?://[^\\s<>()\\[\\]]+|\\\\[!-/:-@\\[-`{-~]|\\*+|~~|\\|")
+
+// SpacingMarkdown performs paranoid text spacing on a Markdown document,
+// such as a README. Only prose is changed, in paragraphs, headings, list
+// items, block quotes and tables. Code blocks, code spans, front matter,
+// link destinations and reference definitions, images, URLs, HTML and
+// backslash escapes are left alone, as is everything else, byte for
+// byte.
+//
+// Code spans are seen by the rules as the text they hold, and the syntax
+// of links, emphasis and HTML as nothing, so "使用`go test`與**bold**字" becomes
+// "使用 `go test` 與 **bold** 字".
+func (s *Spacer) SpacingMarkdown(src []byte) []byte {
+	var out strings.Builder
+	lines := splitLines(string(src))
+	fence := ""      // the fence of the code block in effect, if any
+	comment := false // whether inside an HTML comment
+	blank := true    // whether the previous line is blank
+	list := false    // whether inside a list
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+		trimmed := strings.TrimLeft(body, " \t")
+
+		switch {
+		case i == 0 && strings.TrimRight(body, " \t") == "---":
+			// YAML front matter.
+			fence = "---"
+		case fence != "":
+			if fence == "---" && strings.TrimRight(body, " \t") == "---" ||
+				fence != "---" && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+				fence = ""
+			}
+		case comment:
+			comment = !strings.Contains(body, "-->")
+		case trimmed == "":
+			blank = true
+		case mdFence.MatchString(body):
+			fence = mdFence.FindStringSubmatch(body)[1]
+		case blank && !list && (strings.HasPrefix(body, "    ") || strings.HasPrefix(body, "\t")):
+			// An indented code block.
+		case mdSkipped.MatchString(body):
+			if strings.HasPrefix(trimmed, "<!--") {
+				comment = !strings.Contains(body, "-->")
+			}
+			blank = false
+		default:
+			if mdListItem.MatchString(body) {
+				list = true
+			} else if blank && len(trimmed) == len(body) {
+				list = false
+			}
+			blank = false
+			out.WriteString(s.spacingSegments(markdownSegments(body)) + eol)
+			continue
+		}
+		out.WriteString(line)
+	}
+
+	return s.suppressed(src, []byte(out.String()), markupDirective)
+}
+
+// markdownSegments splits a line of Markdown prose into segments.
+func markdownSegments(line string) []segment {
+	var segs []segment
+	last := 0
+	strong := map[string]bool{} // emphasis delimiters left open
+	for _, loc := range mdInline.FindAllStringIndex(line, -1) {
+		if loc[0] > last {
+			segs = append(segs, literal(line[last:loc[0]]))
+		}
+		raw := line[loc[0]:loc[1]]
+		last = loc[1]
+
+		switch {
+		case raw[0] == '`':
+			n := len(raw) - len(strings.TrimLeft(raw, "`"))
+			if !strings.HasSuffix(raw, raw[:n]) || len(raw) < 2*n {
+				segs = append(segs, opaque(raw, raw))
+				continue
+			}
+			segs = append(segs, opaque(raw, raw[n:len(raw)-n]))
+		case raw == "[":
+			segs = append(segs, segment{raw: raw, open: true})
+		case raw[0] == ']':
+			segs = append(segs, opaque(raw, ""))
+		case raw[0] == '*' || raw == "~~":
+			segs = append(segs, segment{raw: raw, open: !strong[raw]})
+			strong[raw] = !strong[raw]
+		case raw == "|":
+			segs = append(segs, opaque(raw, "\n"))
+		case raw[0] == '\\':
+			segs = append(segs, opaque(raw, raw[1:]))
+		case raw[0] == '<' && !strings.Contains(raw, ":"):
+			segs = append(segs, segment{raw: raw, open: raw[1] != '/'})
+		default:
+			// Autolinks, images and URLs.
+			segs = append(segs, opaque(raw, "0"))
+		}
+	}
+	if last < len(line) {
+		segs = append(segs, literal(line[last:]))
+	}
+
+	return segs
+}
+
+// frontMatter returns the number of lines at the start of lines taken by
+// YAML front matter, between two "---" lines, or 0 if there is none.
+func frontMatter(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t\r\n") != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], " \t\r\n") == "---" {
+			return i + 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,74 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type MarkdownTestSuite struct {
+	suite.Suite
+}
+
+func TestMarkdownTestSuite(t *testing.T) {
+	suite.Run(t, new(MarkdownTestSuite))
+}
+
+func (suite *MarkdownTestSuite) spacing(src string) string {
+	return string(pangu.NewSpacer().SpacingMarkdown([]byte(src)))
+}
+
+func (suite *MarkdownTestSuite) TestInline() {
+	suite.Equal("使用 `go test` 與 **bold** 字", suite.spacing("使用`go test`與**bold**字"))
+	suite.Equal("請看[說明 Doc](https://example.com/中文abc) 網站", suite.spacing("請看[說明Doc](https://example.com/中文abc)網站"))
+	suite.Equal("圖片 ![圖片Image](a.png) 和<b>粗體 Bold</b> 與 https://example.com/中文abc", suite.spacing("圖片![圖片Image](a.png)和<b>粗體Bold</b>與https://example.com/中文abc"))
+	suite.Equal("價格 \\$5 與 ``a`b`` 字", suite.spacing("價格\\$5與``a`b``字"))
+	suite.Equal("| 名稱 Name | 說明 |", suite.spacing("| 名稱Name | 說明 |"))
+}
+
+func (suite *MarkdownTestSuite) TestBlocks() {
+	src := "---\n" +
+		"title: 標題Title\n" +
+		"---\n" +
+		"\n" +
+		"# 標題Title\n" +
+		"\n" +
+		"> 當你凝視著bug，bug也凝視著你\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"中文English\")\n" +
+		"```\n" +
+		"\n" +
+		"    indented中文code\n" +
+		"\n" +
+		"- 第1項item\n" +
+		"\n" +
+		"    第2段paragraph\n" +
+		"\n" +
+		"<!-- 註解comment\n" +
+		"還是註解comment -->\n" +
+		"\n" +
+		"[連結link]: https://example.com/中文abc\n"
+	suite.Equal("---\n"+
+		"title: 標題Title\n"+
+		"---\n"+
+		"\n"+
+		"# 標題 Title\n"+
+		"\n"+
+		"> 當你凝視著 bug，bug 也凝視著你\n"+
+		"\n"+
+		"```go\n"+
+		"fmt.Println(\"中文English\")\n"+
+		"```\n"+
+		"\n"+
+		"    indented中文code\n"+
+		"\n"+
+		"- 第 1 項 item\n"+
+		"\n"+
+		"    第 2 段 paragraph\n"+
+		"\n"+
+		"<!-- 註解comment\n"+
+		"還是註解comment -->\n"+
+		"\n"+
+		"[連結link]: https://example.com/中文abc\n", suite.spacing(src))
+}
@@ -0,0 +1,194 @@
+package pangu
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Middleware returns a handler that performs paranoid text spacing on
+// the HTML responses of next, with the default rules. See
+// Spacer.Middleware.
+func Middleware(next http.Handler) http.Handler {
+	return defaultSpacer.Middleware(next)
+}
+
+// Middleware returns a handler that performs paranoid text spacing on
+// the text/html responses of next with SpacingHTML, fixing up their
+// Content-Length and dropping the ETag of the ones that change. Responses
+// encoded with gzip are decoded and encoded again. Other responses,
+// partial ones, responses to HEAD requests and the ones in another
+// charset or encoding are passed through as they are, and so are
+// informational (1xx) headers, such as 103 Early Hints.
+//
+// HTML responses are buffered until next returns, so flushing them early
+// does nothing and hijacking their connection fails. Other responses can
+// be flushed and hijacked as usual.
+func (s *Spacer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hw := &htmlWriter{ResponseWriter: w, spacer: s, head: r.Method == "HEAD"}
+		next.ServeHTTP(hw, r)
+		hw.finish()
+	})
+}
+
+// htmlWriter buffers an HTML response until it is finished, and passes
+// any other response through.
+type htmlWriter struct {
+	http.ResponseWriter
+	spacer *Spacer
+	head   bool
+
+	code    int  // 0 until the header is written
+	decided bool // whether the response is known to be HTML or not
+	html    bool
+	buf     bytes.Buffer
+}
+
+func (w *htmlWriter) WriteHeader(code int) {
+	if w.code != 0 {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		// Informational headers come before the final one.
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.code = code
+	if w.Header().Get("Content-Type") != "" {
+		w.decide(nil)
+	}
+}
+
+func (w *htmlWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if !w.decided {
+		w.decide(p)
+	}
+	if w.html {
+		return w.buf.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush flushes a response known not to be HTML, deciding from its
+// header if nothing was written yet. HTML responses stay buffered.
+func (w *htmlWriter) Flush() {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if !w.decided {
+		w.decide(nil)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); !w.html && ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection of a response not known to be HTML.
+func (w *htmlWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.html {
+		return nil, nil, errors.New("pangu: can't hijack an HTML response")
+	}
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("pangu: the ResponseWriter does not support hijacking")
+	}
+	// The connection is no longer the business of the middleware.
+	w.decided = true
+
+	return h.Hijack()
+}
+
+// Unwrap returns the ResponseWriter wrapped by w, for
+// http.ResponseController.
+func (w *htmlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide tells whether the response is HTML from its header or, like
+// net/http does, from the first bytes of its body, and writes the header
+// of other responses.
+func (w *htmlWriter) decide(p []byte) {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && h.Get("Content-Encoding") == "" && p != nil {
+		h.Set("Content-Type", http.DetectContentType(p))
+	}
+
+	t, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	charset := strings.ToLower(params["charset"])
+	encoding := strings.ToLower(h.Get("Content-Encoding"))
+	w.html = t == "text/html" &&
+		(charset == "" || charset == "utf-8") &&
+		(encoding == "" || encoding == "identity" || encoding == "gzip") &&
+		w.code != http.StatusPartialContent && !w.head
+
+	if !w.html {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+}
+
+// finish writes the header of a response without a body, or the spaced
+// body of an HTML response.
+func (w *htmlWriter) finish() {
+	if w.code == 0 {
+		return
+	}
+	if !w.decided {
+		w.decide(nil)
+	}
+	if !w.html {
+		return
+	}
+
+	body := w.spacingBody(w.buf.Bytes())
+	if !bytes.Equal(body, w.buf.Bytes()) {
+		w.Header().Del("ETag")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.code)
+	w.ResponseWriter.Write(body)
+}
+
+// spacingBody returns body with spacing performed, or body itself if it
+// cannot be decoded or read.
+func (w *htmlWriter) spacingBody(body []byte) []byte {
+	gzipped := strings.EqualFold(w.Header().Get("Content-Encoding"), "gzip")
+
+	src := body
+	if gzipped {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return body
+		}
+		if src, err = ioutil.ReadAll(zr); err != nil {
+			return body
+		}
+	}
+
+	out, err := w.spacer.SpacingHTML(src)
+	if err != nil {
+		return body
+	}
+	if !gzipped {
+		return out
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(out)
+	zw.Close()
+
+	return buf.Bytes()
+}
@@ -0,0 +1,196 @@
+package pangu_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
+
+func (suite *MiddlewareTestSuite) serve(method string, h http.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	pangu.Middleware(h).ServeHTTP(w, httptest.NewRequest(method, "/", nil))
+
+	return w
+}
+
+func (suite *MiddlewareTestSuite) TestHTML() {
+	w := suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", "41")
+		w.Header().Set("ETag", `"41"`)
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<p>當你凝視著<b>bug</b>，")
+		io.WriteString(w, "bug也凝視著你</p>")
+	})
+	suite.Equal(http.StatusNotFound, w.Code)
+	suite.Equal("<p>當你凝視著 <b>bug</b>，bug 也凝視著你</p>", w.Body.String())
+	suite.Equal(strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+	suite.Empty(w.Header().Get("ETag"))
+
+	w = suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"ok"`)
+		io.WriteString(w, "<p>與 PM 戰鬥的人</p>")
+	})
+	suite.Equal(`"ok"`, w.Header().Get("ETag"))
+}
+
+func (suite *MiddlewareTestSuite) TestSniffed() {
+	w := suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<!DOCTYPE html><html><body><p>與PM戰鬥的人</p></body></html>")
+	})
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("<!DOCTYPE html><html><body><p>與 PM 戰鬥的人</p></body></html>", w.Body.String())
+}
+
+func (suite *MiddlewareTestSuite) TestGzip() {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, "<p>與PM戰鬥的人</p>")
+	zw.Close()
+
+	w := suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	})
+	suite.Equal(strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+	zr, err := gzip.NewReader(w.Body)
+	suite.Nil(err)
+	out, err := ioutil.ReadAll(zr)
+	suite.Nil(err)
+	suite.Equal("<p>與 PM 戰鬥的人</p>", string(out))
+}
+
+func (suite *MiddlewareTestSuite) TestPassThrough() {
+	for _, h := range []http.HandlerFunc{
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"text": "與PM戰鬥的人"}`)
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=big5")
+			io.WriteString(w, `{"text": "與PM戰鬥的人"}`)
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, `{"text": "與PM戰鬥的人"}`)
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, `{"text": "與PM戰鬥的人"}`)
+		},
+	} {
+		w := suite.serve("GET", h)
+		suite.Contains(w.Body.String(), `{"text": "與PM戰鬥的人"}`)
+	}
+
+	w := suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	suite.Equal(http.StatusNoContent, w.Code)
+	suite.Equal(0, w.Body.Len())
+}
+
+func (suite *MiddlewareTestSuite) TestFlush() {
+	w := suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: 與PM戰鬥的人\n\n")
+		w.(http.Flusher).Flush()
+	})
+	suite.True(w.Flushed)
+	suite.Equal("data: 與PM戰鬥的人\n\n", w.Body.String())
+
+	w = suite.serve("GET", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<p>與PM")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "戰鬥的人</p>")
+	})
+	suite.False(w.Flushed)
+	suite.Equal("<p>與 PM 戰鬥的人</p>", w.Body.String())
+}
+
+// hijackRecorder is a ResponseRecorder that can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func (suite *MiddlewareTestSuite) TestHijack() {
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	pangu.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err := w.(http.Hijacker).Hijack()
+		suite.NoError(err)
+	})).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	suite.True(w.hijacked)
+	suite.Equal(0, w.Body.Len())
+
+	w = &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	pangu.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<p>與PM戰鬥的人</p>")
+		_, _, err := w.(http.Hijacker).Hijack()
+		suite.Error(err)
+	})).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	suite.False(w.hijacked)
+	suite.Equal("<p>與 PM 戰鬥的人</p>", w.Body.String())
+}
+
+func (suite *MiddlewareTestSuite) TestUnwrap() {
+	rec := httptest.NewRecorder()
+	pangu.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		suite.True(ok)
+		suite.Equal(rec, u.Unwrap())
+	})).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+}
+
+// codeRecorder is a ResponseRecorder that records every status code
+// written, informational ones included.
+type codeRecorder struct {
+	*httptest.ResponseRecorder
+	codes []int
+}
+
+func (w *codeRecorder) WriteHeader(code int) {
+	w.codes = append(w.codes, code)
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func (suite *MiddlewareTestSuite) TestInformational() {
+	w := &codeRecorder{ResponseRecorder: httptest.NewRecorder()}
+	pangu.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<p>與PM戰鬥的人</p>")
+	})).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	suite.Equal([]int{http.StatusEarlyHints, http.StatusNotFound}, w.codes)
+	suite.Equal("<p>與 PM 戰鬥的人</p>", w.Body.String())
+}
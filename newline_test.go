@@ -0,0 +1,214 @@
+package pangu_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type NewlineTestSuite struct {
+	suite.Suite
+}
+
+func TestNewlineTestSuite(t *testing.T) {
+	suite.Run(t, new(NewlineTestSuite))
+}
+
+// modes are the format modes, each with a document using "\n" line
+// endings and its expected output.
+var modes = []struct {
+	name          string
+	fn            func(s *pangu.Spacer, src []byte) ([]byte, error)
+	src, expected string
+}{
+	{
+		"Document",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingDocument(src), nil },
+		"與PM戰鬥的人\n當你凝視著bug\n",
+		"與 PM 戰鬥的人\n當你凝視著 bug\n",
+	},
+	{
+		"Go",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingGo(src) },
+		"package a\n\n// 中文abc\nvar a = \"中文abc\"\n",
+		"package a\n\n// 中文 abc\nvar a = \"中文 abc\"\n",
+	},
+	{
+		"SRT",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingSRT(src), nil },
+		"1\n00:00:01,000 --> 00:00:02,000\n與PM戰鬥的人\n\n2\n00:00:03,000 --> 00:00:04,000\n當你凝視著bug\n",
+		"1\n00:00:01,000 --> 00:00:02,000\n與 PM 戰鬥的人\n\n2\n00:00:03,000 --> 00:00:04,000\n當你凝視著 bug\n",
+	},
+	{
+		"WebVTT",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingWebVTT(src), nil },
+		"WEBVTT\n\nNOTE 註解note\n\n00:01.000 --> 00:02.000\n與PM戰鬥的人\n",
+		"WEBVTT\n\nNOTE 註解note\n\n00:01.000 --> 00:02.000\n與 PM 戰鬥的人\n",
+	},
+	{
+		"PO",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingPO(src), nil },
+		"# 註解comment\nmsgid \"a\"\nmsgstr \"\"\n\"與PM戰鬥的人\"\n",
+		"# 註解comment\nmsgid \"a\"\nmsgstr \"\"\n\"與 PM 戰鬥的人\"\n",
+	},
+	{
+		"JSON",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingJSON(src) },
+		"{\n  \"a\": \"與PM戰鬥的人\"\n}\n",
+		"{\n  \"a\": \"與 PM 戰鬥的人\"\n}\n",
+	},
+	{
+		"YAML",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingYAML(src), nil },
+		"a: 與PM戰鬥的人\nb: |\n  當你凝視著bug\nc: 中文abc\n",
+		"a: 與 PM 戰鬥的人\nb: |\n  當你凝視著 bug\nc: 中文 abc\n",
+	},
+	{
+		"TOML",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingTOML(src), nil },
+		"a = \"與PM戰鬥的人\"\nb = '''\n當你凝視著bug'''\n",
+		"a = \"與 PM 戰鬥的人\"\nb = '''\n當你凝視著 bug'''\n",
+	},
+	{
+		"AndroidXML",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingAndroidXML(src) },
+		"<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n<string name=\"a\">與PM戰鬥的人</string>\n</resources>\n",
+		"<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n<string name=\"a\">與 PM 戰鬥的人</string>\n</resources>\n",
+	},
+	{
+		"AppleStrings",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingAppleStrings(src), nil },
+		"// 註解comment\n\"a\" = \"與PM戰鬥的人\";\n",
+		"// 註解comment\n\"a\" = \"與 PM 戰鬥的人\";\n",
+	},
+	{
+		"XCStrings",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingXCStrings(src) },
+		"{\n\"strings\": {\"a\": {\"localizations\": {\"zh-Hant\": {\"stringUnit\": {\"value\": \"與PM戰鬥的人\"}}}}}\n}\n",
+		"{\n\"strings\": {\"a\": {\"localizations\": {\"zh-Hant\": {\"stringUnit\": {\"value\": \"與 PM 戰鬥的人\"}}}}}\n}\n",
+	},
+	{
+		"XLIFF",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingXLIFF(src) },
+		"<xliff>\n<source>與PM戰鬥的人</source>\n<target>與PM戰鬥的人</target>\n</xliff>\n",
+		"<xliff>\n<source>與PM戰鬥的人</source>\n<target>與 PM 戰鬥的人</target>\n</xliff>\n",
+	},
+	{
+		"XHTML",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingXHTML(src) },
+		"<html>\n<body>\n<p>與PM戰鬥的人\n當你凝視著bug</p>\n</body>\n</html>\n",
+		"<html>\n<body>\n<p>與 PM 戰鬥的人\n當你凝視著 bug</p>\n</body>\n</html>\n",
+	},
+	{
+		"WordprocessingML",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingWordprocessingML(src) },
+		"<w:document>\n<w:p><w:r><w:t>與PM戰鬥的人</w:t></w:r></w:p>\n</w:document>\n",
+		"<w:document>\n<w:p><w:r><w:t>與 PM 戰鬥的人</w:t></w:r></w:p>\n</w:document>\n",
+	},
+	{
+		"OpenDocumentText",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingOpenDocumentText(src) },
+		"<office:text>\n<text:p>與PM戰鬥的人</text:p>\n</office:text>\n",
+		"<office:text>\n<text:p>與 PM 戰鬥的人</text:p>\n</office:text>\n",
+	},
+	{
+		"LaTeX",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingLaTeX(src), nil },
+		"\\section{第1章}\n與PM戰鬥的人\n% 註解comment\n當你凝視著bug\n",
+		"\\section{第 1 章}\n與 PM 戰鬥的人\n% 註解comment\n當你凝視著 bug\n",
+	},
+	{
+		"RST",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingRST(src), nil },
+		"第1章Chapter\n==========\n\n與PM戰鬥的人::\n\n    中文abc\n\n當你凝視著bug\n",
+		"第 1 章 Chapter\n=============\n\n與 PM 戰鬥的人::\n\n    中文abc\n\n當你凝視著 bug\n",
+	},
+	{
+		"AsciiDoc",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingAsciiDoc(src), nil },
+		"= 標題Title\n\n與PM戰鬥的人\n----\n中文abc\n----\n當你凝視著bug\n",
+		"= 標題 Title\n\n與 PM 戰鬥的人\n----\n中文abc\n----\n當你凝視著 bug\n",
+	},
+	{
+		"Markdown",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingMarkdown(src), nil },
+		"# 標題Title\n\n與PM戰鬥的人\n```\n中文abc\n```\n當你凝視著bug\n",
+		"# 標題 Title\n\n與 PM 戰鬥的人\n```\n中文abc\n```\n當你凝視著 bug\n",
+	},
+	{
+		"CSV",
+		func(s *pangu.Spacer, src []byte) ([]byte, error) { return s.SpacingCSV(src, ',') },
+		"title,description\n與PM戰鬥的人,\"當你凝視著bug\n中文abc\"\n",
+		"title,description\n與 PM 戰鬥的人,\"當你凝視著 bug\n中文 abc\"\n",
+	},
+}
+
+// variants returns doc with every combination of byte order mark, line
+// endings and final newline.
+func variants(doc string) map[string]string {
+	docs := map[string]string{}
+	for _, bom := range []string{"", "\ufeff"} {
+		for _, eol := range []string{"\n", "\r\n", "\r"} {
+			for _, final := range []bool{true, false} {
+				text := strings.Replace(doc, "\n", eol, -1)
+				if !final {
+					text = strings.TrimSuffix(text, eol)
+				}
+				docs[fmt.Sprintf("bom=%t eol=%q final=%t", bom != "", eol, final)] = bom + text
+			}
+		}
+	}
+
+	return docs
+}
+
+func (suite *NewlineTestSuite) TestModes() {
+	s := pangu.NewSpacer()
+	for _, mode := range modes {
+		srcs, expected := variants(mode.src), variants(mode.expected)
+		for name, src := range srcs {
+			out, err := mode.fn(s, []byte(src))
+			suite.NoError(err, "%s %s", mode.name, name)
+			suite.Equal(expected[name], string(out), "%s %s", mode.name, name)
+		}
+	}
+}
+
+func (suite *NewlineTestSuite) TestSpacingFile() {
+	dir, err := ioutil.TempDir("", "pangu")
+	checkError(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "a.txt")
+
+	for _, name := range []string{"test_file", "test_file_no_eof_newline"} {
+		src, err := ioutil.ReadFile("_fixtures/" + name + ".txt")
+		checkError(err)
+		expected, err := ioutil.ReadFile("_fixtures/" + name + ".expected.txt")
+		checkError(err)
+
+		srcs, expecteds := variants(string(src)), variants(string(expected))
+		for variant, src := range srcs {
+			checkError(ioutil.WriteFile(filename, []byte(src), 0644))
+			var buf bytes.Buffer
+			suite.NoError(pangu.SpacingFile(filename, &buf))
+			suite.Equal(expecteds[variant], buf.String(), "%s %s", name, variant)
+		}
+	}
+}
+
+func (suite *NewlineTestSuite) TestCheck() {
+	for _, eol := range []string{"\n", "\r\n", "\r"} {
+		text := strings.Replace("ok\nok\n中文abc\n", "\n", eol, -1)
+		problems, err := pangu.NewSpacer().Check(strings.NewReader(text))
+		suite.NoError(err)
+		suite.Require().Len(problems, 1, "eol=%q", eol)
+		suite.Equal(3, problems[0].Line, "eol=%q", eol)
+		suite.Equal(3, problems[0].Column, "eol=%q", eol)
+	}
+}
@@ -0,0 +1,124 @@
+package pangu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+)
+
+// wordText matches the <w:t> elements without attributes, capturing
+// their text.
+var wordText = regexp.MustCompile(`<w:t>([^<]*)</w:t>`)
+
+// SpacingWordprocessingML performs paranoid text spacing on a part of a
+// Word document, such as word/document.xml in a .docx file. The text of
+// each paragraph is spaced as a whole, across <w:r> runs, so "中文" and
+// "English" are spaced even when they are styled differently, without
+// touching the formatting. Only the content of <w:t> elements changes;
+// field codes, deleted text and everything else are left alone, byte for
+// byte. Elements are expected to use the usual "w" prefix.
+func (s *Spacer) SpacingWordprocessingML(src []byte) ([]byte, error) {
+	out, err := s.spacingOffice(src, func(name xml.Name) (text string, content bool) {
+		if name.Space != "w" {
+			return "", false
+		}
+		switch name.Local {
+		case "p":
+			return "\n", false
+		case "tab", "ptab":
+			return "\t", false
+		case "br", "cr":
+			return "\n", false
+		case "t":
+			return "", true
+		}
+		return "", false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return preserveWordSpaces(src, out), nil
+}
+
+// preserveWordSpaces returns out, the spaced version of the Word part src,
+// with xml:space="preserve" set on the <w:t> elements whose text was
+// changed to start or end with a space, which Word drops otherwise.
+// Elements left as they were are not touched.
+func preserveWordSpaces(src, out []byte) []byte {
+	texts := wordText.FindAllSubmatch(src, -1)
+	i := 0
+	return wordText.ReplaceAllFunc(out, func(m []byte) []byte {
+		text := wordText.FindSubmatch(m)[1]
+		changed := i >= len(texts) || !bytes.Equal(texts[i][1], text)
+		i++
+		if changed && len(text) > 0 && (isSpace(text[0]) || isSpace(text[len(text)-1])) {
+			return []byte(`<w:t xml:space="preserve">` + string(text) + `</w:t>`)
+		}
+		return m
+	})
+}
+
+// SpacingOpenDocumentText performs paranoid text spacing on the content
+// of an OpenDocument text, such as content.xml in an .odt file. The text
+// of each paragraph and heading is spaced as a whole, across <text:span>
+// boundaries, without touching the formatting. Elements are expected to
+// use the usual "text" prefix.
+func (s *Spacer) SpacingOpenDocumentText(src []byte) ([]byte, error) {
+	return s.spacingOffice(src, func(name xml.Name) (text string, content bool) {
+		if name.Space != "text" {
+			return "", false
+		}
+		switch name.Local {
+		case "p", "h":
+			return "\n", true
+		case "tab":
+			return "\t", false
+		case "line-break":
+			return "\n", false
+		case "s":
+			return " ", false
+		case "span", "a":
+			return "", true
+		}
+		return "", false
+	})
+}
+
+// spacingOffice performs spacing on an office document, where element
+// tells how the rules see the tags of an element and whether the text
+// directly in it is content. Anything else is seen as nothing.
+func (s *Spacer) spacingOffice(src []byte, element func(xml.Name) (text string, content bool)) ([]byte, error) {
+	tokens, err := xmlTokens(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var segs []segment
+	var stack []bool // whether the text of each open element is content
+	for _, tok := range tokens {
+		switch t := tok.Token.(type) {
+		case xml.StartElement:
+			text, content := element(t.Name)
+			segs = append(segs, opaque(tok.raw, text))
+			stack = append(stack, content)
+		case xml.EndElement:
+			text, _ := element(t.Name)
+			segs = append(segs, opaque(tok.raw, text))
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 && stack[len(stack)-1] && !tok.isCDATA() {
+				segs = append(segs, splitSegments(tok.raw, xmlReference, html.UnescapeString)...)
+				continue
+			}
+			segs = append(segs, opaque(tok.raw, ""))
+		default:
+			segs = append(segs, opaque(tok.raw, ""))
+		}
+	}
+
+	return []byte(s.spacingSegments(segs)), nil
+}
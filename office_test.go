@@ -0,0 +1,51 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type OfficeTestSuite struct {
+	suite.Suite
+}
+
+func TestOfficeTestSuite(t *testing.T) {
+	suite.Run(t, new(OfficeTestSuite))
+}
+
+func (suite *OfficeTestSuite) TestSpacingWordprocessingML() {
+	src := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>中文</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>English</w:t></w:r><w:r><w:t>中文</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">與PM </w:t></w:r><w:r><w:instrText>HYPERLINK "中文abc"</w:instrText></w:r><w:r><w:t>戰鬥&amp;AT</w:t><w:tab/><w:t>人</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>第一段</w:t></w:r></w:p><w:p><w:r><w:t>Second</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>開頭 </w:t></w:r><w:r><w:t> 未改動</w:t></w:r></w:p>` +
+		`</w:body></w:document>`
+	out, err := pangu.NewSpacer().SpacingWordprocessingML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+		`<w:p><w:r><w:t>中文</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> English</w:t></w:r><w:r><w:t xml:space="preserve"> 中文</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t xml:space="preserve">與 PM </w:t></w:r><w:r><w:instrText>HYPERLINK "中文abc"</w:instrText></w:r><w:r><w:t>戰鬥 &amp; AT</w:t><w:tab/><w:t>人</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t>第一段</w:t></w:r></w:p><w:p><w:r><w:t>Second</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t>開頭 </w:t></w:r><w:r><w:t> 未改動</w:t></w:r></w:p>`+
+		`</w:body></w:document>`, string(out))
+}
+
+func (suite *OfficeTestSuite) TestSpacingOpenDocumentText() {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>` +
+		`<text:h text:outline-level="1">第1章Chapter</text:h>` +
+		`<text:p text:style-name="P1">當你凝視著<text:span text:style-name="T1">bug</text:span>，bug也凝視著你</text:p>` +
+		`<text:p>第一段</text:p><text:p>Second</text:p>` +
+		`</office:text></office:body></office:document-content>`
+	out, err := pangu.NewSpacer().SpacingOpenDocumentText([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>`+
+		`<text:h text:outline-level="1">第 1 章 Chapter</text:h>`+
+		`<text:p text:style-name="P1">當你凝視著<text:span text:style-name="T1"> bug</text:span>，bug 也凝視著你</text:p>`+
+		`<text:p>第一段</text:p><text:p>Second</text:p>`+
+		`</office:text></office:body></office:document-content>`, string(out))
+}
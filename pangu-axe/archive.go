@@ -0,0 +1,132 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// rewriteZip writes a copy of the ZIP archive r to w, passing the files
+// for which part returns a function through it. The other files are
+// copied as they are. A mimetype file, as found in EPUB and OpenDocument
+// files, is stored first, uncompressed and without extra fields, as their
+// specifications require.
+func rewriteZip(r *zip.Reader, w io.Writer, part func(name string) func([]byte) ([]byte, error)) error {
+	zw := zip.NewWriter(w)
+
+	files := r.File
+	for i, f := range files {
+		if f.Name != "mimetype" {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		// Written raw, as CreateHeader would add an extra field and a
+		// data descriptor, which the specifications forbid for mimetype.
+		fw, err := zw.CreateRaw(&zip.FileHeader{
+			Name:               f.Name,
+			Method:             zip.Store,
+			ModifiedTime:       f.ModifiedTime,
+			ModifiedDate:       f.ModifiedDate,
+			CRC32:              crc32.ChecksumIEEE(data),
+			CompressedSize64:   uint64(len(data)),
+			UncompressedSize64: uint64(len(data)),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(data); err != nil {
+			return err
+		}
+		files = append(files[:i:i], files[i+1:]...)
+		break
+	}
+
+	for _, f := range files {
+		fn := part(f.Name)
+		if fn == nil {
+			if err := zw.Copy(f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		out, err := fn(data)
+		if err != nil {
+			return err
+		}
+		fh := f.FileHeader
+		fh.CRC32, fh.CompressedSize64, fh.UncompressedSize64 = 0, 0, 0
+		fw, err := zw.CreateHeader(&fh)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(out); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// spacingDOCX performs spacing on the text of a Word document: its body,
+// headers, footers, footnotes and endnotes.
+func spacingDOCX(s *settings, src []byte) ([]byte, error) {
+	return spacingZip(src, func(name string) func([]byte) ([]byte, error) {
+		dir, file := path.Split(name)
+		if dir != "word/" || path.Ext(file) != ".xml" {
+			return nil
+		}
+		for _, prefix := range []string{"document", "header", "footer", "footnotes", "endnotes"} {
+			if strings.HasPrefix(file, prefix) {
+				return s.spacer.SpacingWordprocessingML
+			}
+		}
+		return nil
+	})
+}
+
+// spacingODT performs spacing on the text of an OpenDocument text,
+// including the headers and footers kept with its styles.
+func spacingODT(s *settings, src []byte) ([]byte, error) {
+	return spacingZip(src, func(name string) func([]byte) ([]byte, error) {
+		if name == "content.xml" || name == "styles.xml" {
+			return s.spacer.SpacingOpenDocumentText
+		}
+		return nil
+	})
+}
+
+func spacingZip(src []byte, part func(name string) func([]byte) ([]byte, error)) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = rewriteZip(r, &buf, part); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
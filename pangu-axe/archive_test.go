@@ -0,0 +1,101 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+type zipEntry struct {
+	name, content string
+}
+
+func zipOf(files ...zipEntry) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, _ := w.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: time.Date(2015, 10, 21, 16, 29, 0, 0, time.UTC),
+		})
+		fw.Write([]byte(f.content))
+	}
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func unzip(data []byte) ([]string, map[string]string) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil
+	}
+
+	var names []string
+	contents := map[string]string{}
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		content, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		names = append(names, f.Name)
+		contents[f.Name] = string(content)
+	}
+
+	return names, contents
+}
+
+type ArchiveTestSuite struct {
+	suite.Suite
+	s *settings
+}
+
+func (suite *ArchiveTestSuite) SetupTest() {
+	suite.s = &settings{spacer: pangu.NewSpacer()}
+}
+
+func TestArchiveTestSuite(t *testing.T) {
+	suite.Run(t, new(ArchiveTestSuite))
+}
+
+func (suite *ArchiveTestSuite) TestDOCX() {
+	styles := `<w:styles><w:style><w:name w:val="標題Title"/></w:style></w:styles>`
+	src := zipOf(
+		zipEntry{"[Content_Types].xml", `<Types/>`},
+		zipEntry{"word/document.xml", `<w:document><w:body><w:p><w:r><w:t>中文</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>English</w:t></w:r></w:p></w:body></w:document>`},
+		zipEntry{"word/header1.xml", `<w:hdr><w:p><w:r><w:t>頁首Header</w:t></w:r></w:p></w:hdr>`},
+		zipEntry{"word/styles.xml", styles},
+	)
+	out, err := formatOf("report.docx")(suite.s, src)
+	suite.Require().NoError(err)
+
+	names, contents := unzip(out)
+	suite.Equal([]string{"[Content_Types].xml", "word/document.xml", "word/header1.xml", "word/styles.xml"}, names)
+	suite.Equal(`<w:document><w:body><w:p><w:r><w:t>中文</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> English</w:t></w:r></w:p></w:body></w:document>`, contents["word/document.xml"])
+	suite.Equal(`<w:hdr><w:p><w:r><w:t>頁首 Header</w:t></w:r></w:p></w:hdr>`, contents["word/header1.xml"])
+	suite.Equal(styles, contents["word/styles.xml"])
+}
+
+func (suite *ArchiveTestSuite) TestODT() {
+	src := zipOf(
+		zipEntry{"content.xml", `<office:document-content><office:body><office:text><text:p>與PM戰鬥的人</text:p></office:text></office:body></office:document-content>`},
+		zipEntry{"mimetype", "application/vnd.oasis.opendocument.text"},
+		zipEntry{"meta.xml", `<office:meta><dc:title>標題Title</dc:title></office:meta>`},
+	)
+	out, err := formatOf("report.odt")(suite.s, src)
+	suite.Require().NoError(err)
+
+	names, contents := unzip(out)
+	suite.Equal([]string{"mimetype", "content.xml", "meta.xml"}, names)
+	suite.Equal(`<office:document-content><office:body><office:text><text:p>與 PM 戰鬥的人</text:p></office:text></office:body></office:document-content>`, contents["content.xml"])
+	suite.Equal(`<office:meta><dc:title>標題Title</dc:title></office:meta>`, contents["meta.xml"])
+}
+
+func (suite *ArchiveTestSuite) TestCheck() {
+	_, err := checkFile(suite.s, "../_fixtures/report.docx")
+	suite.EqualError(err, "can't check .docx files")
+}
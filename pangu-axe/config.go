@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/vinta/pangu"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CONFIG_FILES are the names of configuration files, in order of
+// preference. Both are TOML.
+var CONFIG_FILES = []string{".pangu.toml", ".pangurc"}
+
+// config is the content of a single configuration file. See the
+// package documentation for an example.
+type config struct {
+	Root           bool     `toml:"root"`
+	Enable         []string `toml:"enable"`
+	Disable        []string `toml:"disable"`
+	Locale         string   `toml:"locale"`
+	Protect        []string `toml:"protect"`
+	Include        []string `toml:"include"`
+	Exclude        []string `toml:"exclude"`
+	Output         string   `toml:"output"`
+	GoFuncs        []string `toml:"go_funcs"`
+	JSONPaths      []string `toml:"json_paths"`
+	CSVColumns     []string `toml:"csv_columns"`
+	Encoding       string   `toml:"encoding"`
+	OutputEncoding string   `toml:"output_encoding"`
+
+	dir string
+}
+
+// settings are the merged configurations that apply to one file.
+type settings struct {
+	spacer         *pangu.Spacer
+	include        []glob
+	exclude        []glob
+	output         string
+	goFuncs        []string
+	jsonPaths      []string
+	csvColumns     []string
+	encoding       string
+	outputEncoding string
+}
+
+// glob is a pattern relative to the directory of the config defining it.
+type glob struct {
+	dir     string
+	pattern string
+}
+
+var configCache = map[string]*config{}
+
+var settingsCache = map[string]*settings{}
+
+// dictionary holds the protected terms loaded with the --dict flag.
+var dictionary []string
+
+// readConfig returns the config file in dir, or nil if there is none.
+func readConfig(dir string) (*config, error) {
+	if c, ok := configCache[dir]; ok {
+		return c, nil
+	}
+
+	var c *config
+	for _, name := range CONFIG_FILES {
+		filename := filepath.Join(dir, name)
+		if _, err := os.Stat(filename); err != nil {
+			continue
+		}
+
+		c = &config{dir: dir}
+		if _, err := toml.DecodeFile(filename, c); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+		break
+	}
+	configCache[dir] = c
+
+	return c, nil
+}
+
+// resolveConfig walks up from dir and merges every config file found,
+// until it reaches the file system root or a config with root = true.
+func resolveConfig(dir string) (*settings, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := settingsCache[dir]; ok {
+		return s, nil
+	}
+	start := dir
+
+	var chain []*config
+	for {
+		c, err := readConfig(dir)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			chain = append(chain, c)
+			if c.Root {
+				break
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	s := &settings{spacer: pangu.NewSpacer()}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := s.merge(chain[i]); err != nil {
+			return nil, err
+		}
+	}
+	s.spacer.Protect(dictionary...)
+	settingsCache[start] = s
+
+	return s, nil
+}
+
+// loadDictionaries replaces the protected terms with those read from
+// the given dictionary files.
+func loadDictionaries(filenames []string) error {
+	configCache = map[string]*config{}
+	settingsCache = map[string]*settings{}
+	dictionary = nil
+
+	for _, filename := range filenames {
+		fr, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		terms, err := pangu.ReadDictionary(fr)
+		fr.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		dictionary = append(dictionary, terms...)
+	}
+
+	return nil
+}
+
+func (s *settings) merge(c *config) error {
+	if c.Locale != "" {
+		if err := s.spacer.UseProfile(c.Locale); err != nil {
+			return fmt.Errorf("%s: %s", c.dir, err)
+		}
+	}
+	if err := s.spacer.Disable(c.Disable...); err != nil {
+		return fmt.Errorf("%s: %s", c.dir, err)
+	}
+	if err := s.spacer.Enable(c.Enable...); err != nil {
+		return fmt.Errorf("%s: %s", c.dir, err)
+	}
+
+	for _, expr := range c.Protect {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("%s: %s", c.dir, err)
+		}
+		s.spacer.ProtectPattern(re)
+	}
+
+	if c.Include != nil {
+		s.include = nil
+		for _, p := range c.Include {
+			s.include = append(s.include, glob{c.dir, p})
+		}
+	}
+	for _, p := range c.Exclude {
+		s.exclude = append(s.exclude, glob{c.dir, p})
+	}
+
+	if c.GoFuncs != nil {
+		s.goFuncs = c.GoFuncs
+	}
+	if c.JSONPaths != nil {
+		s.jsonPaths = c.JSONPaths
+	}
+	if c.CSVColumns != nil {
+		s.csvColumns = c.CSVColumns
+	}
+
+	for _, name := range []string{c.Encoding, c.OutputEncoding} {
+		if err := checkEncoding(name); err != nil {
+			return fmt.Errorf("%s: %s", c.dir, err)
+		}
+	}
+	if c.Encoding != "" {
+		s.encoding = c.Encoding
+	}
+	if c.OutputEncoding != "" {
+		s.outputEncoding = c.OutputEncoding
+	}
+
+	switch c.Output {
+	case "":
+	case "prefix", "inplace", "stdout", "stderr":
+		s.output = c.Output
+	default:
+		return fmt.Errorf("%s: unknown output %q", c.dir, c.Output)
+	}
+
+	return nil
+}
+
+// skip reports whether filename is filtered out by include or exclude.
+func (s *settings) skip(filename string) bool {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+
+	for _, g := range s.exclude {
+		if g.match(abs) {
+			return true
+		}
+	}
+	if len(s.include) == 0 {
+		return false
+	}
+	for _, g := range s.include {
+		if g.match(abs) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match reports whether the absolute filename matches g. Patterns
+// without a slash match the base name only; others match the path
+// relative to the config directory, and "**" matches any number of
+// directories.
+func (g glob) match(filename string) bool {
+	rel, err := filepath.Rel(g.dir, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if !strings.Contains(g.pattern, "/") {
+		ok, _ := path.Match(g.pattern, path.Base(rel))
+		return ok
+	}
+
+	return matchSegments(strings.Split(g.pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
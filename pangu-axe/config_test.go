@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *ConfigTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "pangu-axe")
+	suite.Require().NoError(err)
+	suite.dir = dir
+	loadDictionaries(nil)
+}
+
+func (suite *ConfigTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (suite *ConfigTestSuite) write(name, content string) string {
+	filename := filepath.Join(suite.dir, name)
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+	suite.Require().NoError(ioutil.WriteFile(filename, []byte(content), 0644))
+
+	return filename
+}
+
+func (suite *ConfigTestSuite) TestNoConfig() {
+	suite.write(".pangu.toml", "root = true\n")
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+	suite.Equal("前面 #H2G2 後面", s.spacer.SpacingText("前面#H2G2後面"))
+	suite.Equal("", s.output)
+	suite.False(s.skip(filepath.Join(suite.dir, "a.txt")))
+}
+
+func (suite *ConfigTestSuite) TestOverrides() {
+	suite.write(".pangu.toml", `
+root = true
+disable = ["hash"]
+protect = ['iPhone\S+']
+output = "stdout"
+exclude = ["vendor/**"]
+`)
+	suite.write("docs/.pangurc", `
+enable = ["hash"]
+output = "inplace"
+include = ["*.md"]
+`)
+
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+	suite.Equal("前面#H2G2 後面", s.spacer.SpacingText("前面#H2G2後面"))
+	suite.Equal("買 iPhone手機殼", s.spacer.SpacingText("買iPhone手機殼"))
+	suite.Equal("stdout", s.output)
+	suite.True(s.skip(filepath.Join(suite.dir, "vendor/x/a.txt")))
+	suite.False(s.skip(filepath.Join(suite.dir, "a.txt")))
+
+	s, err = resolveConfig(filepath.Join(suite.dir, "docs"))
+	suite.NoError(err)
+	suite.Equal("前面 #H2G2 後面", s.spacer.SpacingText("前面#H2G2後面"))
+	suite.Equal("買 iPhone手機殼", s.spacer.SpacingText("買iPhone手機殼"))
+	suite.Equal("inplace", s.output)
+	suite.True(s.skip(filepath.Join(suite.dir, "docs/a.txt")))
+	suite.False(s.skip(filepath.Join(suite.dir, "docs/a.md")))
+}
+
+func (suite *ConfigTestSuite) TestLocale() {
+	suite.write(".pangu.toml", "root = true\ndisable = [\"hash\"]\n")
+	suite.write("ja/.pangu.toml", "locale = \"ja\"\nenable = [\"hash\"]\n")
+	suite.write("ja/zh/.pangu.toml", "locale = \"zh\"\n")
+
+	s, err := resolveConfig(filepath.Join(suite.dir, "ja"))
+	suite.NoError(err)
+	suite.Equal("Go 言語(v1.22)を使う", s.spacer.SpacingText("Go言語(v1.22)を使う"))
+	suite.Equal("テスト #H2G2 です", s.spacer.SpacingText("テスト#H2G2です"))
+
+	s, err = resolveConfig(filepath.Join(suite.dir, "ja/zh"))
+	suite.NoError(err)
+	suite.Equal("與 PM 戰鬥的人", s.spacer.SpacingText("與PM戰鬥的人"))
+	suite.Equal("前面 #H2G2 後面", s.spacer.SpacingText("前面#H2G2後面"))
+}
+
+func (suite *ConfigTestSuite) TestInvalidConfig() {
+	suite.write(".pangu.toml", "root = true\ndisable = [\"nope\"]\n")
+	_, err := resolveConfig(suite.dir)
+	suite.Error(err)
+
+	loadDictionaries(nil)
+	suite.write(".pangu.toml", "root = true\nlocale = \"tlh\"\n")
+	_, err = resolveConfig(suite.dir)
+	suite.Error(err)
+
+	loadDictionaries(nil)
+	suite.write(".pangu.toml", "root = true\noutput = \"nowhere\"\n")
+	_, err = resolveConfig(suite.dir)
+	suite.Error(err)
+}
+
+func (suite *ConfigTestSuite) TestInplace() {
+	suite.write(".pangu.toml", "root = true\noutput = \"inplace\"\n")
+	filename := suite.write("a.txt", "與PM戰鬥的人\n")
+
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+
+	errc := make(chan error, 1)
+	processFile(errc, filename, "", s)
+	suite.NoError(<-errc)
+
+	content, err := ioutil.ReadFile(filename)
+	suite.NoError(err)
+	suite.Equal("與 PM 戰鬥的人\n", string(content))
+}
+
+func (suite *ConfigTestSuite) TestMatchSegments() {
+	suite.True(matchSegments([]string{"**", "*.go"}, []string{"a", "b", "c.go"}))
+	suite.True(matchSegments([]string{"a", "**"}, []string{"a"}))
+	suite.False(matchSegments([]string{"a", "*.go"}, []string{"a", "b", "c.go"}))
+}
+
+func (suite *ConfigTestSuite) TestDictionary() {
+	suite.write(".pangu.toml", "root = true\n")
+	dict := suite.write("brands.txt", "# brands\n3D打印\n")
+
+	suite.NoError(loadDictionaries([]string{dict}))
+	defer loadDictionaries(nil)
+
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+	suite.Equal("用 3D打印機", s.spacer.SpacingText("用3D打印機"))
+}
+
+func (suite *ConfigTestSuite) TestGoFuncs() {
+	suite.write(".pangu.toml", "root = true\ngo_funcs = [\"T\"]\n")
+	filename := suite.write("a.go", "package a\n\n// 中文abc\nvar a, b = T(\"中文abc\"), \"中文abc\"\n")
+
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+
+	problems, err := checkFile(s, filename)
+	suite.NoError(err)
+	suite.Len(problems, 2)
+}
+
+func (suite *ConfigTestSuite) TestDirectives() {
+	suite.write(".pangu.toml", "root = true\n")
+	filename := suite.write("a.go", "package a\n\n// pangu-ignore-next-line\nvar a = \"中文abc\"\n\n// pangu-ignore-next-line\nvar b = 1\n")
+
+	s, err := resolveConfig(suite.dir)
+	suite.NoError(err)
+
+	problems, err := checkFile(s, filename)
+	suite.NoError(err)
+	suite.Equal([]pangu.Problem{{
+		Line:    6,
+		Column:  1,
+		Message: "unused pangu-ignore-next-line",
+		Rule:    "unused-directive",
+	}}, problems)
+
+	var buf bytes.Buffer
+	suite.NoError(spacingFile(s, filename, &buf))
+	suite.Equal("package a\n\n// pangu-ignore-next-line\nvar a = \"中文abc\"\n\n// pangu-ignore-next-line\nvar b = 1\n", buf.String())
+}
+
+func (suite *ConfigTestSuite) TestCSVColumns() {
+	suite.write(".pangu.toml", "root = true\ncsv_columns = [\"title\"]\n")
+	filename := suite.write("a.csv", "sku,title\n中文abc,中文abc\n")
+
+	_, configs := resolveFiles([]string{filename}, overrides{})
+	problems, err := checkFile(configs[filename], filename)
+	suite.NoError(err)
+	suite.Equal([]pangu.Problem{{
+		Line:      2,
+		Column:    9,
+		Message:   "missing space",
+		Rule:      "missing-space",
+		Fix:       &pangu.Edit{Start: 26, End: 26, New: " "},
+		EndLine:   2,
+		EndColumn: 9,
+	}}, problems)
+
+	_, configs = resolveFiles([]string{filename}, overrides{columns: "sku,title"})
+	problems, err = checkFile(configs[filename], filename)
+	suite.NoError(err)
+	suite.Len(problems, 2)
+}
@@ -2,6 +2,101 @@
 //
 // It separates the chaos between CJK (Chinese, Japanese, Korean) and half-width characters.
 //
+// Settings are read from a .pangu.toml or .pangurc file (both TOML),
+// looked up from the directory of each processed file towards the root.
+// Files closer to the processed file override the ones further away,
+// and a file with "root = true" stops the lookup:
+//
+// 	root = true
+// 	locale = "zh"               # see pangu.Profiles, applied before enable and disable
+// 	disable = ["hash"]          # see pangu.Rules
+// 	protect = ['iPhone\S+']     # regular expressions left untouched
+// 	include = ["*.txt", "docs/**"]
+// 	exclude = ["vendor/**"]
+// 	output = "inplace"          # prefix, inplace, stdout or stderr
+// 	go_funcs = ["i18n.T"]       # only space Go strings passed to these
+// 	json_paths = ["**.title"]   # only space JSON values at these key paths
+// 	csv_columns = ["title"]     # only space CSV and TSV fields in these columns
+// 	encoding = "big5"           # utf-8, auto, big5, gbk, gb18030, shift_jis, euc-jp or euc-kr
+// 	output_encoding = "utf-8"   # defaults to the encoding of each file
+//
+// Some files are processed by format-aware modes chosen by their
+// extension, instead of line by line:
+//
+// 	.adoc       AsciiDoc prose, not literals, attributes or macros, also for .asciidoc
+// 	.csv        fields, keeping quoting and line endings, also for .tsv
+// 	.docx       text of the body, headers, footers and notes, across runs
+// 	.go         comments and string literals only
+// 	.json       string values only
+// 	.md         Markdown prose, not code, URLs or HTML, also for .markdown
+// 	.odt        text of the content, headers and footers, across spans
+// 	.po         msgstr values only, also for .pot
+// 	.rst        reStructuredText prose, not literals, directives or targets
+// 	.srt        cue text only
+// 	.strings    values of Apple .strings files only
+// 	.tex        LaTeX text and text arguments, not commands, math or verbatim
+// 	.toml       string values only
+// 	.vtt        cue text only
+// 	.xcstrings  localized values of Xcode string catalogs only
+// 	.xlf        target segments of XLIFF files only, also for .xliff
+// 	.xml        values of Android string resources only
+// 	.yaml       scalar string values only, also for .yml
+//
+// The pangu-disable, pangu-enable, pangu-ignore-next-line and
+// pangu-disable-file directives, placed at the start of a comment, leave
+// lines alone in plain text and in the format-aware modes for formats
+// with comments, see pangu.DirectiveDisable.
+//
+// The --columns, --encoding and --output-encoding flags of the file and
+// check commands override csv_columns, encoding and output_encoding, as in
+// --columns title,description. With "auto", the encoding of each file is
+// guessed from its content. Files are written back in their own encoding
+// unless another one is given, and characters that cannot be encoded, or
+// bytes that cannot be decoded, are reported and the file is left alone.
+//
+// The --format flag of the check command chooses how problems are
+// reported, each with its file, line, column, rule and fix if any: text
+// by default, json, sarif for code scanning services, checkstyle, or
+// github for annotations in GitHub Actions. The rules are missing-space,
+// extra-space, wrong-space and unused-directive.
+//
+// The staged command checks the content of the files staged in git, read
+// from the index with the local git binary, and reports problems like the
+// check command does. With --changed-lines, only the lines changed since
+// HEAD are checked, and with --fix, problems are fixed and the fixed
+// content is staged; files with unstaged changes are only fixed in the
+// index. Binary files are left alone, and so are files without a
+// format-aware mode, other than .txt files, unless include patterns are
+// set. "pangu-axe git-hook install" installs a pre-commit hook running the
+// staged command, with the same --fix and --changed-lines flags.
+//
+// Word and OpenDocument files are written as new documents with their
+// formatting untouched, and cannot be checked.
+//
+// The epub command performs spacing on the XHTML content documents listed
+// in the spine of EPUB e-books, leaving the other files as they are.
+//
+// The serve command serves the spacing modes over HTTP, for other services
+// to share, on the address given with --addr (":8080" by default):
+//
+// 	POST /text      plain text, line by line
+// 	POST /html      HTML documents or fragments, see pangu.SpacingHTML
+// 	POST /markdown  Markdown, as for .md files
+// 	POST /batch     {"requests": [{"mode": "html", "text": "..."}]}
+// 	GET  /healthz   "ok" while serving
+// 	GET  /metrics   request counters in the Prometheus text format
+//
+// The spacing endpoints answer a raw body with a raw body, and a JSON
+// {"text": "..."} object, sent as application/json, with another one.
+// Bodies larger than --max-bytes, 1 MiB by default, are refused, and
+// requests taking longer than --timeout, 10s by default, are given up.
+//
+// The lsp command runs a language server over stdin and stdout, for any
+// editor speaking the Language Server Protocol. Open documents get a
+// warning for every missing or extra space, with code actions to fix one
+// or all of them, and formatting fixes them all. Documents are spaced like
+// files with the same name, with the same settings and format-aware modes.
+//
 // If you want to use pangu in your Go programs, see
 // 	https://github.com/vinta/pangu
 package main
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ENCODINGS are the character encodings of files, besides utf-8 and
+// auto, which detects the encoding of each file.
+var ENCODINGS = map[string]encoding.Encoding{
+	"big5":      traditionalchinese.Big5,
+	"euc-jp":    japanese.EUCJP,
+	"euc-kr":    korean.EUCKR,
+	"gb18030":   simplifiedchinese.GB18030,
+	"gbk":       simplifiedchinese.GBK,
+	"shift_jis": japanese.ShiftJIS,
+}
+
+// detected are the encodings tried by auto, in order of preference.
+var detected = []string{"big5", "gbk", "gb18030", "shift_jis", "euc-jp", "euc-kr"}
+
+// frequent holds some of the most frequent Chinese characters, both
+// traditional and simplified, telling apart Big5 and GBK text.
+const frequent = "的一是不了人我在有他這这個个們们中來来上大為为和國国地到以說说時时要就出會会可也你對对生能而子那得於于著着下自之年過过發发後后作裡里用道行所然家種种事成方多經经麼么去法學学如都同現现當当沒没動动面起看定天分還还進进好小部其些主樣样理心她本前開开但因只從从想實实"
+
+// checkEncoding returns an error if name is not a known encoding.
+func checkEncoding(name string) error {
+	switch name {
+	case "", "auto", "utf-8":
+		return nil
+	}
+	if _, ok := ENCODINGS[name]; !ok {
+		return fmt.Errorf("unknown encoding %q", name)
+	}
+
+	return nil
+}
+
+// decode decodes src from the encoding called name, detecting it if name
+// is auto. It returns the text and the name of its encoding, or an error
+// listing where src is not valid in that encoding.
+func decode(name string, src []byte) (string, string, error) {
+	switch name {
+	case "", "utf-8":
+		return string(src), "utf-8", nil
+	case "auto":
+		return detect(src)
+	}
+
+	text, err := ENCODINGS[name].NewDecoder().String(string(src))
+	if err != nil {
+		return "", "", err
+	}
+	if bad := positions(text, func(r rune) bool { return r == utf8.RuneError }); len(bad) > 0 {
+		return "", "", fmt.Errorf("invalid %s at %s", name, strings.Join(bad, ", "))
+	}
+
+	return text, name, nil
+}
+
+// detect guesses the encoding of src: UTF-8 if it is valid, otherwise
+// the legacy encoding giving the most frequent Chinese characters, kana
+// or hangul, which is a heuristic that works on real text but not on
+// the shortest snippets.
+func detect(src []byte) (string, string, error) {
+	if utf8.Valid(src) {
+		return string(src), "utf-8", nil
+	}
+
+	best, bestText, bestScore := "", "", -1
+	for _, name := range detected {
+		text, err := ENCODINGS[name].NewDecoder().String(string(src))
+		if err != nil || strings.ContainsRune(text, utf8.RuneError) {
+			continue
+		}
+		score := 0
+		for _, r := range text {
+			if strings.ContainsRune(frequent, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestText, bestScore = name, text, score
+		}
+	}
+	if best == "" {
+		return "", "", fmt.Errorf("unknown encoding, try --encoding")
+	}
+
+	return bestText, best, nil
+}
+
+// encode encodes text in the encoding called name, or returns an error
+// listing the characters that encoding cannot represent.
+func encode(name string, text string) ([]byte, error) {
+	if name == "" || name == "utf-8" || name == "auto" {
+		return []byte(text), nil
+	}
+
+	enc := ENCODINGS[name]
+	out, err := enc.NewEncoder().String(text)
+	if err == nil {
+		return []byte(out), nil
+	}
+
+	unsupported := map[rune]bool{}
+	bad := positions(text, func(r rune) bool {
+		if r < utf8.RuneSelf {
+			return false
+		}
+		if _, ok := unsupported[r]; !ok {
+			_, err := enc.NewEncoder().String(string(r))
+			unsupported[r] = err != nil
+		}
+		return unsupported[r]
+	})
+
+	return nil, fmt.Errorf("can't encode in %s: %s", name, strings.Join(bad, ", "))
+}
+
+// positions returns the line:column positions of the characters of text
+// matching f, followed by the character itself.
+func positions(text string, f func(rune) bool) []string {
+	var found []string
+	line, column := 1, 1
+	for _, r := range text {
+		if f(r) {
+			found = append(found, fmt.Sprintf("%d:%d %q", line, column, r))
+		}
+		column++
+		if r == '\n' {
+			line, column = line+1, 1
+		}
+	}
+
+	return found
+}
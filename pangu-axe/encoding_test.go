@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type EncodingTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *EncodingTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "pangu-axe")
+	suite.Require().NoError(err)
+	suite.dir = dir
+}
+
+func (suite *EncodingTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestEncodingTestSuite(t *testing.T) {
+	suite.Run(t, new(EncodingTestSuite))
+}
+
+func (suite *EncodingTestSuite) TestBig5() {
+	src, _ := traditionalchinese.Big5.NewEncoder().String("與PM戰鬥的人\n")
+	filename := filepath.Join(suite.dir, "a.txt")
+	suite.Require().NoError(ioutil.WriteFile(filename, []byte(src), 0644))
+
+	var buf bytes.Buffer
+	s := &settings{spacer: pangu.NewSpacer(), encoding: "big5"}
+	suite.NoError(spacingFile(s, filename, &buf))
+	expected, _ := traditionalchinese.Big5.NewEncoder().String("與 PM 戰鬥的人\n")
+	suite.Equal(expected, buf.String())
+
+	buf.Reset()
+	s.outputEncoding = "utf-8"
+	suite.NoError(spacingFile(s, filename, &buf))
+	suite.Equal("與 PM 戰鬥的人\n", buf.String())
+
+	problems, err := checkFile(s, filename)
+	suite.NoError(err)
+	suite.Len(problems, 2)
+}
+
+func (suite *EncodingTestSuite) TestDetect() {
+	big5, _ := traditionalchinese.Big5.NewEncoder().String("當你凝視著深淵的時候，深淵也在凝視著你")
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String("当你凝视着深渊的时候，深渊也在凝视着你")
+
+	text, name, err := decode("auto", []byte(big5))
+	suite.NoError(err)
+	suite.Equal("big5", name)
+	suite.Equal("當你凝視著深淵的時候，深淵也在凝視著你", text)
+
+	text, name, err = decode("auto", []byte(gbk))
+	suite.NoError(err)
+	suite.Equal("gbk", name)
+	suite.Equal("当你凝视着深渊的时候，深渊也在凝视着你", text)
+
+	_, name, err = decode("auto", []byte("與PM戰鬥的人"))
+	suite.NoError(err)
+	suite.Equal("utf-8", name)
+}
+
+func (suite *EncodingTestSuite) TestRoundTrip() {
+	_, err := encode("big5", "與PM戰鬥\n这个人")
+	suite.EqualError(err, `can't encode in big5: 2:1 '这'`)
+
+	_, _, err = decode("big5", []byte("中\xff文"))
+	suite.Error(err)
+
+	suite.EqualError(checkEncoding("latin1"), `unknown encoding "latin1"`)
+}
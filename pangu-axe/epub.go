@@ -0,0 +1,128 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+)
+
+// A container is META-INF/container.xml of an EPUB.
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// A packageDocument is the OPF file of an EPUB.
+type packageDocument struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// processEPUB writes a copy of the EPUB filename to o, performing spacing
+// on the XHTML content documents listed in its spine.
+func processEPUB(filename, o string, s *settings) error {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	fw, err := os.Create(prefixFilename(filename, o))
+	if err != nil {
+		return err
+	}
+	err = spacingEPUB(s, &r.Reader, fw)
+	if cerr := fw.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// spacingEPUB writes a copy of the EPUB container r to w. Only the XHTML
+// content documents in the spine are changed.
+func spacingEPUB(s *settings, r *zip.Reader, w io.Writer) error {
+	files := map[string]*zip.File{}
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	if files["mimetype"] == nil {
+		return errors.New("not an EPUB: missing mimetype")
+	}
+	content, err := contentDocuments(files)
+	if err != nil {
+		return err
+	}
+
+	return rewriteZip(r, w, func(name string) func([]byte) ([]byte, error) {
+		if content[name] {
+			return s.spacer.SpacingXHTML
+		}
+		return nil
+	})
+}
+
+// contentDocuments returns the names of the XHTML files in the spine of
+// the EPUB made of files.
+func contentDocuments(files map[string]*zip.File) (map[string]bool, error) {
+	f := files["META-INF/container.xml"]
+	if f == nil {
+		return nil, errors.New("not an EPUB: missing META-INF/container.xml")
+	}
+	var c container
+	if err := unmarshalZipFile(f, &c); err != nil {
+		return nil, err
+	}
+
+	content := map[string]bool{}
+	for _, root := range c.Rootfiles {
+		f := files[root.FullPath]
+		if f == nil {
+			return nil, errors.New("not an EPUB: missing " + root.FullPath)
+		}
+		var pkg packageDocument
+		if err := unmarshalZipFile(f, &pkg); err != nil {
+			return nil, err
+		}
+
+		hrefs := map[string]string{}
+		for _, item := range pkg.Manifest {
+			if item.MediaType == "application/xhtml+xml" {
+				hrefs[item.ID] = item.Href
+			}
+		}
+		for _, ref := range pkg.Spine {
+			href, ok := hrefs[ref.IDRef]
+			if !ok {
+				continue
+			}
+			if u, err := url.PathUnescape(href); err == nil {
+				href = u
+			}
+			content[path.Join(path.Dir(root.FullPath), href)] = true
+		}
+	}
+
+	return content, nil
+}
+
+func unmarshalZipFile(f *zip.File, v interface{}) error {
+	data, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+
+	return xml.Unmarshal(data, v)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"testing"
+)
+
+type EPUBTestSuite struct {
+	suite.Suite
+}
+
+func TestEPUBTestSuite(t *testing.T) {
+	suite.Run(t, new(EPUBTestSuite))
+}
+
+var epubFiles = []zipEntry{
+	{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`},
+	{"OEBPS/content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata/>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="text/%E7%AC%AC1%E7%AB%A0.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`},
+	{"OEBPS/nav.xhtml", `<html><body><p>第1章Chapter</p></body></html>`},
+	{"OEBPS/text/第1章.xhtml", `<html><body><p>與PM戰鬥的人</p></body></html>`},
+	{"mimetype", "application/epub+zip"},
+}
+
+func (suite *EPUBTestSuite) epub() *zip.Reader {
+	data := zipOf(epubFiles...)
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	suite.Require().NoError(err)
+
+	return r
+}
+
+func (suite *EPUBTestSuite) TestSpacingEPUB() {
+	var buf bytes.Buffer
+	err := spacingEPUB(&settings{spacer: pangu.NewSpacer()}, suite.epub(), &buf)
+	suite.Require().NoError(err)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	suite.Require().NoError(err)
+	suite.Equal("mimetype", r.File[0].Name)
+	suite.Equal(zip.Store, r.File[0].Method)
+	// The local file header of mimetype has no flags and no extra field,
+	// so its content starts at offset 38.
+	header := buf.Bytes()
+	suite.Equal(uint16(0), binary.LittleEndian.Uint16(header[6:8]))
+	suite.Equal(uint16(0), binary.LittleEndian.Uint16(header[28:30]))
+	suite.Equal([]byte("mimetype"), header[30:38])
+	suite.Equal([]byte("application/epub+zip"), header[38:58])
+
+	contents := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		suite.Require().NoError(err)
+		data, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+	suite.Len(contents, len(epubFiles))
+	suite.Equal(`<html><body><p>與 PM 戰鬥的人</p></body></html>`, contents["OEBPS/text/第1章.xhtml"])
+	suite.Equal(`<html><body><p>第1章Chapter</p></body></html>`, contents["OEBPS/nav.xhtml"])
+	suite.Equal(epubFiles[1].content, contents["OEBPS/content.opf"])
+}
+
+func (suite *EPUBTestSuite) TestNotEPUB() {
+	data := zipOf(zipEntry{"a.txt", ""})
+	r, _ := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	err := spacingEPUB(&settings{spacer: pangu.NewSpacer()}, r, ioutil.Discard)
+	suite.EqualError(err, "not an EPUB: missing mimetype")
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/vinta/pangu"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// A format performs format-aware spacing on the whole content of a file.
+type format func(s *settings, src []byte) ([]byte, error)
+
+// formats maps file extensions to their format-aware modes. Files with
+// other extensions are spaced line by line with pangu.SpacingFile.
+var formats = map[string]format{
+	".adoc": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingAsciiDoc(src), nil
+	},
+	".asciidoc": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingAsciiDoc(src), nil
+	},
+	".csv": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingCSV(src, ',', s.csvColumns...)
+	},
+	".docx": spacingDOCX,
+	".go": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingGo(src, s.goFuncs...)
+	},
+	".json": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingJSON(src, s.jsonPaths...)
+	},
+	".markdown": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingMarkdown(src), nil
+	},
+	".md": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingMarkdown(src), nil
+	},
+	".odt": spacingODT,
+	".po": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingPO(src), nil
+	},
+	".pot": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingPO(src), nil
+	},
+	".rst": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingRST(src), nil
+	},
+	".srt": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingSRT(src), nil
+	},
+	".strings": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingAppleStrings(src), nil
+	},
+	".tex": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingLaTeX(src), nil
+	},
+	".toml": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingTOML(src), nil
+	},
+	".tsv": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingCSV(src, '\t', s.csvColumns...)
+	},
+	".vtt": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingWebVTT(src), nil
+	},
+	".xcstrings": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingXCStrings(src)
+	},
+	".xlf": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingXLIFF(src)
+	},
+	".xliff": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingXLIFF(src)
+	},
+	".xml": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingAndroidXML(src)
+	},
+	".yaml": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingYAML(src), nil
+	},
+	".yml": func(s *settings, src []byte) ([]byte, error) {
+		return s.spacer.SpacingYAML(src), nil
+	},
+}
+
+// archives are the formats of binary files, which cannot be checked.
+var archives = map[string]bool{
+	".docx": true,
+	".odt":  true,
+}
+
+func formatOf(filename string) format {
+	return formats[strings.ToLower(filepath.Ext(filename))]
+}
+
+// spacingFile writes the processed content of filename to w, in the
+// output encoding of s.
+func spacingFile(s *settings, filename string, w io.Writer) error {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if archives[strings.ToLower(filepath.Ext(filename))] {
+		out, err := formatOf(filename)(s, src)
+		if err == nil {
+			_, err = w.Write(out)
+		}
+		return err
+	}
+
+	text, enc, err := decode(s.encoding, src)
+	if err != nil {
+		return err
+	}
+	out, err := spacing(s, filename, text)
+	if err != nil {
+		return err
+	}
+	if s.outputEncoding != "" {
+		enc = s.outputEncoding
+	}
+	data, err := encode(enc, out)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, bytes.NewReader(data))
+
+	return err
+}
+
+// spacing performs spacing on text, the decoded content of filename, with
+// the format-aware mode of its extension if any.
+func spacing(s *settings, filename, text string) (string, error) {
+	f := formatOf(filename)
+	if f == nil {
+		return string(s.spacer.SpacingDocument([]byte(text))), nil
+	}
+	out, err := f(s, []byte(text))
+
+	return string(out), err
+}
+
+// checkFile reports where spacing would change filename.
+func checkFile(s *settings, filename string) ([]pangu.Problem, error) {
+	if ext := strings.ToLower(filepath.Ext(filename)); archives[ext] {
+		return nil, fmt.Errorf("can't check %s files", ext)
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	text, _, err := decode(s.encoding, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkText(s, filename, text)
+}
+
+// checkText reports where spacing would change text, the decoded content
+// of filename.
+func checkText(s *settings, filename, text string) ([]pangu.Problem, error) {
+	f := formatOf(filename)
+	if f == nil {
+		return s.spacer.Check(strings.NewReader(text))
+	}
+
+	return s.spacer.CheckMode([]byte(text), func(spacer *pangu.Spacer, src []byte) ([]byte, error) {
+		raw := *s
+		raw.spacer = spacer
+		return f(&raw, src)
+	})
+}
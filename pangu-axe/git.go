@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HOOK_MARKER marks the git hooks installed by pangu-axe, which may be
+// replaced without --force.
+const HOOK_MARKER = "# Installed by pangu-axe git-hook install."
+
+// gitHunk matches the header of a hunk of a unified diff, capturing the
+// start and length of its lines in the new file.
+var gitHunk = regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// git runs the local git binary with args in dir, feeding it stdin if not
+// nil, and returns its output. Pathspecs are taken literally, since they
+// are always file names, which may hold glob characters.
+func git(dir string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_LITERAL_PATHSPECS=1")
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return out, nil
+}
+
+// gitTop returns the top directory of the work tree containing dir.
+func gitTop(dir string) (string, error) {
+	out, err := git(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// A stagedFile is a file added, copied, modified or renamed in the index
+// of a git repository.
+type stagedFile struct {
+	path string // relative to the top of the work tree, with slashes
+	mode string
+	blob string // object name of the staged content
+}
+
+// stagedFiles lists the staged files of the work tree at top, leaving out
+// symbolic links and submodules.
+func stagedFiles(top string) ([]stagedFile, error) {
+	out, err := git(top, nil, "diff", "--cached", "--raw", "-z", "--no-abbrev", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var files []stagedFile
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		// :<old mode> SP <new mode> SP <old object> SP <new object> SP
+		// <status> NUL <path> NUL, with the old path first for copies and
+		// renames
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 5 || i+1 >= len(fields) {
+			return nil, fmt.Errorf("git diff: unexpected output %q", fields[i])
+		}
+		i++
+		if (meta[4][0] == 'C' || meta[4][0] == 'R') && i+1 < len(fields) {
+			i++
+		}
+		if meta[1] == "120000" || meta[1] == "160000" {
+			continue
+		}
+		files = append(files, stagedFile{path: fields[i], mode: meta[1], blob: meta[3]})
+	}
+
+	return files, nil
+}
+
+// changedLines returns the lines of the staged content of path that were
+// added or changed since HEAD, numbered from 1.
+func changedLines(top, path string) (map[int]bool, error) {
+	out, err := git(top, nil, "diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff", "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := map[int]bool{}
+	for _, m := range gitHunk.FindAllStringSubmatch(string(out), -1) {
+		start, _ := strconv.Atoi(m[1])
+		n := 1
+		if m[2] != "" {
+			n, _ = strconv.Atoi(m[2])
+		}
+		for i := start; i < start+n; i++ {
+			lines[i] = true
+		}
+	}
+
+	return lines, nil
+}
+
+// unstaged reports whether the file at path differs in the work tree from
+// its staged content, as git sees it once its filters are applied.
+func unstaged(top, path string) (bool, error) {
+	_, err := git(top, nil, "diff", "--quiet", "--", path)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return true, nil
+	}
+
+	return false, err
+}
+
+// gitLineNumbers maps the line numbers of text, as in pangu.Problem, to
+// the ones git gives in diffs. They differ in files with lone "\r" line
+// endings, which git does not see as line endings at all.
+func gitLineNumbers(text string) []int {
+	numbers := []int{0, 1}
+	line := 1
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\n':
+			line++
+			numbers = append(numbers, line)
+		case text[i] == '\r' && !strings.HasPrefix(text[i+1:], "\n"):
+			numbers = append(numbers, line)
+		}
+	}
+
+	return numbers
+}
+
+// skipStaged reports whether the staged file named filename, with the
+// content src, is left alone. Binary files are, and if no include patterns
+// are set, only files with a format-aware mode and .txt files are
+// processed, so that source code in other languages is never spaced as
+// plain text.
+func skipStaged(s *settings, filename string, src []byte) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if archives[ext] || bytes.IndexByte(src, 0) >= 0 {
+		return true
+	}
+
+	return len(s.include) == 0 && formatOf(filename) == nil && ext != ".txt"
+}
+
+// processStaged checks the staged content of f, reporting only the
+// problems on changed lines if onlyChanged. If fix, the problems that can
+// be fixed are fixed in the index and, unless the file has unstaged
+// changes, in the work tree too, checked out by git so that its filters
+// such as core.autocrlf apply; the other problems are returned, along with
+// the number of fixed ones.
+func processStaged(top string, f stagedFile, s *settings, fix, onlyChanged bool) ([]pangu.Problem, int, error) {
+	filename := filepath.Join(top, filepath.FromSlash(f.path))
+	src, err := git(top, nil, "cat-file", "blob", f.blob)
+	if err != nil {
+		return nil, 0, err
+	}
+	if skipStaged(s, filename, src) {
+		return nil, 0, nil
+	}
+
+	text, enc, err := decode(s.encoding, src)
+	if err != nil {
+		return nil, 0, err
+	}
+	problems, err := checkText(s, f.path, text)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if onlyChanged {
+		lines, err := changedLines(top, f.path)
+		if err != nil {
+			return nil, 0, err
+		}
+		numbers := gitLineNumbers(text)
+		var changed []pangu.Problem
+		for _, p := range problems {
+			if p.Line < len(numbers) && lines[numbers[p.Line]] {
+				changed = append(changed, p)
+			}
+		}
+		problems = changed
+	}
+	if !fix {
+		return problems, 0, nil
+	}
+
+	var edits []pangu.Edit
+	var left []pangu.Problem
+	for _, p := range problems {
+		if p.Fix != nil {
+			edits = append(edits, *p.Fix)
+		} else {
+			left = append(left, p)
+		}
+	}
+	if len(edits) == 0 {
+		return left, 0, nil
+	}
+
+	dirty, err := unstaged(top, f.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := encode(enc, applyEdits(text, edits))
+	if err != nil {
+		return nil, 0, err
+	}
+	out, err := git(top, data, "hash-object", "-w", "--no-filters", "--stdin")
+	if err != nil {
+		return nil, 0, err
+	}
+	blob := strings.TrimSpace(string(out))
+	if _, err := git(top, nil, "update-index", "--cacheinfo", f.mode+","+blob+","+f.path); err != nil {
+		return nil, 0, err
+	}
+
+	if !dirty {
+		if _, err := git(top, nil, "checkout-index", "-f", "--", f.path); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return left, len(edits), nil
+}
+
+// errProblems is returned by checkStaged when it reported problems.
+var errProblems = errors.New("problems found")
+
+// checkStaged processes the staged files of the work tree at top, with
+// the overrides o, and reports the problems left with report. Fixed files
+// are listed on stderr.
+func checkStaged(top string, files []stagedFile, o overrides, fix, onlyChanged bool, report reporter) error {
+	filenames := make([]string, len(files))
+	for i, f := range files {
+		filenames[i] = filepath.Join(top, filepath.FromSlash(f.path))
+	}
+	_, configs := resolveFiles(filenames, o)
+
+	var findings []finding
+	for i, f := range files {
+		s, ok := configs[filenames[i]]
+		if !ok {
+			continue
+		}
+		problems, fixed, err := processStaged(top, f, s, fix, onlyChanged)
+		if err != nil {
+			return fmt.Errorf("%s: %s", f.path, err)
+		}
+		if fixed > 0 {
+			fmt.Fprintf(os.Stderr, "%s: fixed %d problems\n", f.path, fixed)
+		}
+		for _, p := range problems {
+			findings = append(findings, finding{f.path, p})
+		}
+	}
+
+	if err := report(os.Stdout, findings); err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return errProblems
+	}
+
+	return nil
+}
+
+// applyEdits returns text with the edits, sorted and not overlapping,
+// applied.
+func applyEdits(text string, edits []pangu.Edit) string {
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(text[last:e.Start])
+		b.WriteString(e.New)
+		last = e.End
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// installHook installs a pre-commit hook running the staged command with
+// args in the repository containing dir, and returns its file name. A
+// hook that was not installed by pangu-axe is only replaced if force.
+func installHook(dir string, force bool, args ...string) (string, error) {
+	out, err := git(dir, nil, "rev-parse", "--git-path", "hooks/pre-commit")
+	if err != nil {
+		return "", err
+	}
+	filename := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+
+	if old, err := ioutil.ReadFile(filename); err == nil && !force && !bytes.Contains(old, []byte(HOOK_MARKER)) {
+		return "", fmt.Errorf("%s already exists, use --force to replace it", filename)
+	}
+
+	command := []string{NAME, "staged"}
+	for _, arg := range args {
+		command = append(command, shellQuote(arg))
+	}
+	hook := "#!/bin/sh\n" + HOOK_MARKER + "\nexec " + strings.Join(command, " ") + "\n"
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filename, []byte(hook), 0755); err != nil {
+		return "", err
+	}
+
+	return filename, os.Chmod(filename, 0755)
+}
+
+// shellQuote quotes arg for sh, so that it is read as one word as it is.
+func shellQuote(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type GitTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestGitTestSuite(t *testing.T) {
+	suite.Run(t, new(GitTestSuite))
+}
+
+func (suite *GitTestSuite) SetupTest() {
+	if _, err := exec.LookPath("git"); err != nil {
+		suite.T().Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "pangu-axe")
+	suite.Require().NoError(err)
+	suite.dir = dir
+	configCache = map[string]*config{}
+	settingsCache = map[string]*settings{}
+
+	suite.git("init", "-q")
+	suite.write(".pangu.toml", "root = true\n")
+	suite.write("a.txt", "第一行\n第二行\n")
+	suite.git("add", ".")
+	suite.git("-c", "user.name=pangu", "-c", "user.email=pangu@example.com", "commit", "-q", "-m", "init")
+}
+
+func (suite *GitTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *GitTestSuite) git(args ...string) string {
+	out, err := git(suite.dir, nil, args...)
+	suite.Require().NoError(err)
+
+	return string(out)
+}
+
+func (suite *GitTestSuite) write(name, content string) {
+	suite.Require().NoError(ioutil.WriteFile(filepath.Join(suite.dir, name), []byte(content), 0644))
+}
+
+func (suite *GitTestSuite) read(name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(suite.dir, name))
+	suite.Require().NoError(err)
+
+	return string(data)
+}
+
+func (suite *GitTestSuite) staged(name string) stagedFile {
+	files, err := stagedFiles(suite.dir)
+	suite.Require().NoError(err)
+	for _, f := range files {
+		if f.path == name {
+			return f
+		}
+	}
+	suite.FailNow("not staged: " + name)
+
+	return stagedFile{}
+}
+
+func (suite *GitTestSuite) TestStagedFiles() {
+	suite.write("a.txt", "第一行\n與PM戰鬥的人\n")
+	suite.write("b.txt", "中文abc\n")
+	suite.write("c.txt", "中文abc\n")
+	suite.git("add", "a.txt", "b.txt")
+
+	files, err := stagedFiles(suite.dir)
+	suite.NoError(err)
+	suite.Len(files, 2)
+	suite.Equal("a.txt", files[0].path)
+	suite.Equal("100644", files[0].mode)
+	suite.Equal("b.txt", files[1].path)
+
+	lines, err := changedLines(suite.dir, "a.txt")
+	suite.NoError(err)
+	suite.Equal(map[int]bool{2: true}, lines)
+}
+
+func (suite *GitTestSuite) TestGlobCharacters() {
+	suite.write("ab.txt", "第一行\n第二行\n")
+	suite.git("add", "ab.txt")
+	suite.git("-c", "user.name=pangu", "-c", "user.email=pangu@example.com", "commit", "-q", "-m", "ab")
+	suite.write("a[b].txt", "中文abc\n")
+	suite.git("add", "a[b].txt")
+
+	files, err := stagedFiles(suite.dir)
+	suite.NoError(err)
+	suite.Len(files, 1)
+	suite.Equal("a[b].txt", files[0].path)
+
+	suite.write("ab.txt", "第一行\n與PM戰鬥的人\n")
+	suite.git("add", "ab.txt")
+	lines, err := changedLines(suite.dir, "a[b].txt")
+	suite.NoError(err)
+	suite.Equal(map[int]bool{1: true}, lines)
+}
+
+func (suite *GitTestSuite) TestRenamed() {
+	suite.git("mv", "a.txt", "b.txt")
+
+	files, err := stagedFiles(suite.dir)
+	suite.NoError(err)
+	suite.Len(files, 1)
+	suite.Equal("b.txt", files[0].path)
+}
+
+func (suite *GitTestSuite) TestCheck() {
+	suite.write("a.txt", "第一行abc\n與PM戰鬥的人\n")
+	suite.git("add", "a.txt")
+	suite.write("a.txt", "unstaged")
+	s, err := resolveConfig(suite.dir)
+	suite.Require().NoError(err)
+
+	problems, fixed, err := processStaged(suite.dir, suite.staged("a.txt"), s, false, false)
+	suite.NoError(err)
+	suite.Equal(0, fixed)
+	suite.Len(problems, 3)
+
+	problems, _, err = processStaged(suite.dir, suite.staged("a.txt"), s, false, true)
+	suite.NoError(err)
+	suite.Len(problems, 3)
+
+	suite.git("-c", "user.name=pangu", "-c", "user.email=pangu@example.com", "commit", "-q", "-m", "a")
+	suite.write("a.txt", "第一行abc\n與PM戰鬥的人\n第三行abc\n")
+	suite.git("add", "a.txt")
+	problems, _, err = processStaged(suite.dir, suite.staged("a.txt"), s, false, true)
+	suite.NoError(err)
+	suite.Len(problems, 1)
+	suite.Equal(3, problems[0].Line)
+}
+
+func (suite *GitTestSuite) TestCheckCR() {
+	suite.write("b.txt", "第一行\r第二行\r")
+	suite.git("add", "b.txt")
+	suite.git("-c", "user.name=pangu", "-c", "user.email=pangu@example.com", "commit", "-q", "-m", "b")
+	suite.write("b.txt", "第一行\r第二行\r中文abc\r")
+	suite.git("add", "b.txt")
+	s, err := resolveConfig(suite.dir)
+	suite.Require().NoError(err)
+
+	// git sees the whole file as a single changed line.
+	problems, _, err := processStaged(suite.dir, suite.staged("b.txt"), s, false, true)
+	suite.NoError(err)
+	suite.Len(problems, 1)
+	suite.Equal(3, problems[0].Line)
+	suite.Equal(3, problems[0].Column)
+
+	suite.Equal([]int{0, 1, 1, 2, 2}, gitLineNumbers("a\rb\r\nc\r"))
+}
+
+func (suite *GitTestSuite) TestFix() {
+	suite.write("a.txt", "第一行\n與PM戰鬥的人\n")
+	suite.write("b.txt", "中文abc\n")
+	suite.write("c.py", "print('中文abc')\n")
+	suite.git("add", ".")
+	suite.write("b.txt", "中文abc\n未暫存unstaged\n")
+	s, err := resolveConfig(suite.dir)
+	suite.Require().NoError(err)
+
+	for _, name := range []string{"a.txt", "b.txt", "c.py"} {
+		problems, _, err := processStaged(suite.dir, suite.staged(name), s, true, false)
+		suite.NoError(err)
+		suite.Empty(problems)
+	}
+
+	suite.Equal("第一行\n與 PM 戰鬥的人\n", suite.git("show", ":a.txt"))
+	suite.Equal("第一行\n與 PM 戰鬥的人\n", suite.read("a.txt"))
+	suite.Equal("中文 abc\n", suite.git("show", ":b.txt"))
+	suite.Equal("中文abc\n未暫存unstaged\n", suite.read("b.txt"))
+	suite.Equal("print('中文abc')\n", suite.git("show", ":c.py"))
+}
+
+func (suite *GitTestSuite) TestFixCRLF() {
+	suite.git("config", "core.autocrlf", "true")
+	suite.write("b.txt", "中文abc\r\n第二行\r\n")
+	suite.git("add", "b.txt")
+	s, err := resolveConfig(suite.dir)
+	suite.Require().NoError(err)
+
+	_, fixed, err := processStaged(suite.dir, suite.staged("b.txt"), s, true, false)
+	suite.NoError(err)
+	suite.Equal(1, fixed)
+	suite.Equal("中文 abc\n第二行\n", suite.git("show", ":b.txt"))
+	suite.Equal("中文 abc\r\n第二行\r\n", suite.read("b.txt"))
+	suite.Equal("A  b.txt\n", suite.git("status", "--porcelain", "--", "b.txt"))
+}
+
+func (suite *GitTestSuite) TestInstallHook() {
+	filename, err := installHook(suite.dir, false, "--fix")
+	suite.NoError(err)
+	suite.Equal(filepath.Join(suite.dir, ".git", "hooks", "pre-commit"), filename)
+	suite.Equal("#!/bin/sh\n"+HOOK_MARKER+"\nexec pangu-axe staged '--fix'\n", suite.read(".git/hooks/pre-commit"))
+	fi, err := os.Stat(filename)
+	suite.NoError(err)
+	suite.Equal(os.FileMode(0755), fi.Mode().Perm())
+
+	_, err = installHook(suite.dir, false)
+	suite.NoError(err)
+
+	suite.write(".git/hooks/pre-commit", "#!/bin/sh\nmake lint\n")
+	_, err = installHook(suite.dir, false)
+	suite.Error(err)
+	_, err = installHook(suite.dir, true, "--changed-lines")
+	suite.NoError(err)
+	suite.Contains(suite.read(".git/hooks/pre-commit"), "exec pangu-axe staged '--changed-lines'\n")
+}
+
+func (suite *GitTestSuite) TestInstallHookQuoting() {
+	args := []string{"--exclude", "docs/it's *.md", "$HOME;", "a\nb"}
+	filename, err := installHook(suite.dir, false, args...)
+	suite.Require().NoError(err)
+
+	// A fake pangu-axe prints the arguments the hook passes it.
+	suite.Require().NoError(os.Mkdir(filepath.Join(suite.dir, "bin"), 0755))
+	suite.write("bin/"+NAME, "#!/bin/sh\nshift\nprintf '%s|' \"$@\"\n")
+	suite.Require().NoError(os.Chmod(filepath.Join(suite.dir, "bin", NAME), 0755))
+	cmd := exec.Command(filename)
+	cmd.Env = append(os.Environ(), "PATH="+filepath.Join(suite.dir, "bin")+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	out, err := cmd.Output()
+	suite.NoError(err)
+	suite.Equal(strings.Join(args, "|")+"|", string(out))
+}
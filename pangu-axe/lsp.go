@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/vinta/pangu"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSON-RPC error codes used by the language server.
+const (
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+	lspRequestFailed  = -32803
+)
+
+// lspMessage is a JSON-RPC request, notification or response.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspCodeAction struct {
+	Title       string          `json:"title"`
+	Kind        string          `json:"kind"`
+	Diagnostics []lspDiagnostic `json:"diagnostics,omitempty"`
+	IsPreferred bool            `json:"isPreferred,omitempty"`
+	Edit        struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+// lspDocument identifies a text document, and holds its text when opened.
+type lspDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+// lspServer is a language server speaking LSP over a pair of streams,
+// usually stdin and stdout. Open documents get diagnostics for every
+// place where spacing would change them, along with code actions and
+// formatting to fix them.
+type lspServer struct {
+	r *bufio.Reader
+	w io.Writer
+
+	docs     map[string]*lspText // open documents, by URI
+	utf8     bool                // whether positions count bytes, not UTF-16 code units
+	shutdown bool
+}
+
+// runLSP serves LSP on r and w until the exit notification, or the end of
+// r, and returns the exit code expected by the client.
+func runLSP(r io.Reader, w io.Writer) (int, error) {
+	l := &lspServer{r: bufio.NewReader(r), w: w, docs: map[string]*lspText{}}
+	for {
+		msg, err := l.read()
+		if err == io.EOF {
+			return 1, nil
+		}
+		if err != nil {
+			return 1, err
+		}
+		if msg.Method == "exit" {
+			if l.shutdown {
+				return 0, nil
+			}
+			return 1, nil
+		}
+		if err := l.handle(msg); err != nil {
+			return 1, err
+		}
+	}
+}
+
+// read reads a message, framed by a Content-Length header.
+func (l *lspServer) read() (*lspMessage, error) {
+	header, err := textproto.NewReader(l.r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(l.r, body); err != nil {
+		return nil, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func (l *lspServer) write(msg lspMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(l.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+
+	return err
+}
+
+func (l *lspServer) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return l.write(lspMessage{Method: method, Params: data})
+}
+
+// handle dispatches msg, answering it if it is a request.
+func (l *lspServer) handle(msg *lspMessage) error {
+	var result interface{}
+	var rerr *lspError
+	switch msg.Method {
+	case "initialize":
+		result, rerr = l.initialize(msg.Params)
+	case "shutdown":
+		l.shutdown = true
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didClose":
+		return l.sync(msg.Method, msg.Params)
+	case "textDocument/codeAction":
+		result, rerr = l.codeAction(msg.Params)
+	case "textDocument/formatting":
+		result, rerr = l.formatting(msg.Params)
+	default:
+		rerr = &lspError{lspMethodNotFound, "method not found: " + msg.Method}
+	}
+
+	if msg.ID == nil {
+		// Notifications are never answered.
+		return nil
+	}
+	resp := lspMessage{ID: msg.ID, Error: rerr}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+
+	return l.write(resp)
+}
+
+func (l *lspServer) initialize(params json.RawMessage) (interface{}, *lspError) {
+	var p struct {
+		Capabilities struct {
+			General struct {
+				PositionEncodings []string `json:"positionEncodings"`
+			} `json:"general"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &lspError{lspInvalidParams, err.Error()}
+	}
+
+	encoding := "utf-16"
+	for _, enc := range p.Capabilities.General.PositionEncodings {
+		if enc == "utf-8" {
+			encoding = enc
+			l.utf8 = true
+		}
+	}
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"positionEncoding": encoding,
+			"textDocumentSync": 1, // full
+			"codeActionProvider": map[string]interface{}{
+				"codeActionKinds": []string{"quickfix", "source.fixAll"},
+			},
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{
+			"name":    NAME,
+			"version": VERSION,
+		},
+	}, nil
+}
+
+// sync keeps track of the text of open documents, and publishes their
+// diagnostics.
+func (l *lspServer) sync(method string, params json.RawMessage) error {
+	var p struct {
+		TextDocument   lspDocument `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+
+	uri := p.TextDocument.URI
+	diagnostics := []lspDiagnostic{}
+	switch method {
+	case "textDocument/didOpen":
+		l.docs[uri] = newLSPText(p.TextDocument.Text)
+	case "textDocument/didChange":
+		if n := len(p.ContentChanges); n > 0 {
+			l.docs[uri] = newLSPText(p.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		delete(l.docs, uri)
+	}
+	if t, ok := l.docs[uri]; ok {
+		edits, err := l.edits(uri, t.text)
+		if err != nil {
+			// Tell why there are no diagnostics, such as a syntax error
+			// in a Go or JSON file.
+			diagnostics = append(diagnostics, lspDiagnostic{
+				Severity: 1, // error
+				Source:   NAME,
+				Message:  err.Error(),
+			})
+		}
+		for _, e := range edits {
+			diagnostics = append(diagnostics, l.diagnostic(t, e))
+		}
+	}
+
+	return l.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// edits returns the edits that spacing makes to text, the content of the
+// document at uri, with the settings and format-aware mode of its file.
+func (l *lspServer) edits(uri, text string) ([]pangu.Edit, error) {
+	filename := uriFilename(uri)
+	s, err := resolveConfig(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	if s.skip(filename) || archives[strings.ToLower(filepath.Ext(filename))] {
+		return nil, nil
+	}
+
+	out, err := spacing(s, filename, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return pangu.Edits(text, out), nil
+}
+
+// uriFilename returns the file name of a file: URI, or the path of any
+// other URI, such as untitled:Untitled-1, relative to the working
+// directory.
+func uriFilename(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "."
+	}
+	if u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+
+	return filepath.Base(u.Opaque + u.Path)
+}
+
+func (l *lspServer) diagnostic(t *lspText, e pangu.Edit) lspDiagnostic {
+	// Insertions are shown on the characters around them, to be visible.
+	start, end := e.Start, e.End
+	if start == end {
+		if _, n := utf8.DecodeLastRuneInString(t.text[:start]); n > 0 {
+			start -= n
+		}
+		if _, n := utf8.DecodeRuneInString(t.text[end:]); n > 0 {
+			end += n
+		}
+	}
+
+	return lspDiagnostic{
+		Range:    lspRange{l.position(t, start), l.position(t, end)},
+		Severity: 2, // warning
+		Code:     e.Rule(),
+		Source:   NAME,
+		Message:  e.Message(),
+	}
+}
+
+func (l *lspServer) textEdit(t *lspText, e pangu.Edit) lspTextEdit {
+	return lspTextEdit{lspRange{l.position(t, e.Start), l.position(t, e.End)}, e.New}
+}
+
+// lspText is the text of an open document, with the offsets where its
+// lines start, so that positions are found without scanning it.
+type lspText struct {
+	text   string
+	starts []int
+}
+
+func newLSPText(text string) *lspText {
+	t := &lspText{text: text, starts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' || text[i] == '\r' && (i+1 == len(text) || text[i+1] != '\n') {
+			t.starts = append(t.starts, i+1)
+		}
+	}
+
+	return t
+}
+
+// position returns the LSP position of the byte offset in t.
+func (l *lspServer) position(t *lspText, offset int) lspPosition {
+	var pos lspPosition
+	pos.Line = sort.SearchInts(t.starts, offset+1) - 1
+
+	line := t.text[t.starts[pos.Line]:offset]
+	if l.utf8 {
+		pos.Character = len(line)
+	} else {
+		pos.Character = len(utf16.Encode([]rune(line)))
+	}
+
+	return pos
+}
+
+// offset returns the byte offset in t of the LSP position pos.
+func (l *lspServer) offset(t *lspText, pos lspPosition) int {
+	if pos.Line >= len(t.starts) {
+		return len(t.text)
+	}
+
+	text := t.text
+	i := t.starts[pos.Line]
+	for n := 0; n < pos.Character && i < len(text) && text[i] != '\n' && text[i] != '\r'; {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		if l.utf8 {
+			n += size
+		} else {
+			n += len(utf16.Encode([]rune{r}))
+		}
+	}
+
+	return i
+}
+
+// document returns the text of the open document at uri, along with its
+// edits.
+func (l *lspServer) document(uri string) (*lspText, []pangu.Edit, *lspError) {
+	t, ok := l.docs[uri]
+	if !ok {
+		return nil, nil, &lspError{lspInvalidParams, "document not open: " + uri}
+	}
+	edits, err := l.edits(uri, t.text)
+	if err != nil {
+		return nil, nil, &lspError{lspRequestFailed, err.Error()}
+	}
+
+	return t, edits, nil
+}
+
+// codeAction offers to fix each problem in the requested range, and all
+// the problems of the document at once.
+func (l *lspServer) codeAction(params json.RawMessage) (interface{}, *lspError) {
+	var p struct {
+		TextDocument lspDocument `json:"textDocument"`
+		Range        lspRange    `json:"range"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &lspError{lspInvalidParams, err.Error()}
+	}
+	uri := p.TextDocument.URI
+	t, edits, rerr := l.document(uri)
+	if rerr != nil {
+		return nil, rerr
+	}
+
+	actions := []lspCodeAction{}
+	start, end := l.offset(t, p.Range.Start), l.offset(t, p.Range.End)
+	var all []lspTextEdit
+	for _, e := range edits {
+		te := l.textEdit(t, e)
+		all = append(all, te)
+
+		d := l.diagnostic(t, e)
+		if ds, de := l.offset(t, d.Range.Start), l.offset(t, d.Range.End); de < start || ds > end {
+			continue
+		}
+		a := lspCodeAction{
+			Title:       "Fix " + e.Message(),
+			Kind:        "quickfix",
+			Diagnostics: []lspDiagnostic{d},
+			IsPreferred: true,
+		}
+		a.Edit.Changes = map[string][]lspTextEdit{uri: {te}}
+		actions = append(actions, a)
+	}
+	if len(all) > 0 {
+		a := lspCodeAction{Title: "Fix all spacing problems", Kind: "source.fixAll"}
+		a.Edit.Changes = map[string][]lspTextEdit{uri: all}
+		actions = append(actions, a)
+	}
+
+	return actions, nil
+}
+
+func (l *lspServer) formatting(params json.RawMessage) (interface{}, *lspError) {
+	var p struct {
+		TextDocument lspDocument `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &lspError{lspInvalidParams, err.Error()}
+	}
+	t, edits, rerr := l.document(p.TextDocument.URI)
+	if rerr != nil {
+		return nil, rerr
+	}
+
+	tes := []lspTextEdit{}
+	for _, e := range edits {
+		tes = append(tes, l.textEdit(t, e))
+	}
+
+	return tes, nil
+}
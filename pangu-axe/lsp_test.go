@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type LSPTestSuite struct {
+	suite.Suite
+}
+
+func TestLSPTestSuite(t *testing.T) {
+	suite.Run(t, new(LSPTestSuite))
+}
+
+// run sends the messages to a language server, and returns its exit code
+// and the messages it sent back.
+func (suite *LSPTestSuite) run(messages ...string) (int, []map[string]interface{}) {
+	var in, out bytes.Buffer
+	for _, msg := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	code, err := runLSP(&in, &out)
+	suite.Nil(err)
+
+	var replies []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		}
+		suite.Nil(err)
+		n, err := strconv.Atoi(header.Get("Content-Length"))
+		suite.Nil(err)
+		body := make([]byte, n)
+		_, err = io.ReadFull(r, body)
+		suite.Nil(err)
+		var reply map[string]interface{}
+		suite.Nil(json.Unmarshal(body, &reply))
+		replies = append(replies, reply)
+	}
+
+	return code, replies
+}
+
+func (suite *LSPTestSuite) jsonOf(v interface{}) string {
+	data, err := json.Marshal(v)
+	suite.Nil(err)
+
+	return string(data)
+}
+
+const lspOpen = `{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": "untitled:a.txt", "text": "# 標題\n與PM戰鬥的人"}}}`
+
+func (suite *LSPTestSuite) TestLifecycle() {
+	code, replies := suite.run(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"capabilities": {}}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "workspace/symbol", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "method": "exit"}`,
+	)
+	suite.Equal(0, code)
+	suite.Len(replies, 3)
+	suite.Equal("utf-16", replies[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})["positionEncoding"])
+	suite.Equal(float64(-32601), replies[1]["error"].(map[string]interface{})["code"])
+	suite.Contains(replies[2], "result")
+	suite.Nil(replies[2]["result"])
+
+	code, _ = suite.run(`{"jsonrpc": "2.0", "method": "exit"}`)
+	suite.Equal(1, code)
+}
+
+func (suite *LSPTestSuite) TestDiagnostics() {
+	_, replies := suite.run(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"capabilities": {}}}`,
+		lspOpen,
+		`{"jsonrpc": "2.0", "method": "textDocument/didChange", "params": {"textDocument": {"uri": "untitled:a.txt"}, "contentChanges": [{"text": "與PM戰鬥的人"}]}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didClose", "params": {"textDocument": {"uri": "untitled:a.txt"}}}`,
+	)
+	suite.Len(replies, 4)
+	suite.Equal("textDocument/publishDiagnostics", replies[1]["method"])
+	suite.JSONEq(`{"uri": "untitled:a.txt", "diagnostics": [
+		{"range": {"start": {"line": 1, "character": 0}, "end": {"line": 1, "character": 2}}, "severity": 2, "code": "missing-space", "source": "pangu-axe", "message": "missing space"},
+		{"range": {"start": {"line": 1, "character": 2}, "end": {"line": 1, "character": 4}}, "severity": 2, "code": "missing-space", "source": "pangu-axe", "message": "missing space"}
+	]}`, suite.jsonOf(replies[1]["params"]))
+	suite.Len(replies[2]["params"].(map[string]interface{})["diagnostics"], 2)
+	suite.JSONEq(`{"uri": "untitled:a.txt", "diagnostics": []}`, suite.jsonOf(replies[3]["params"]))
+}
+
+func (suite *LSPTestSuite) TestCodeActionAndFormatting() {
+	_, replies := suite.run(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"capabilities": {"general": {"positionEncodings": ["utf-8", "utf-16"]}}}}`,
+		lspOpen,
+		`{"jsonrpc": "2.0", "id": 2, "method": "textDocument/codeAction", "params": {"textDocument": {"uri": "untitled:a.txt"}, "range": {"start": {"line": 1, "character": 0}, "end": {"line": 1, "character": 0}}, "context": {"diagnostics": []}}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "textDocument/formatting", "params": {"textDocument": {"uri": "untitled:a.txt"}, "options": {"tabSize": 4, "insertSpaces": true}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "textDocument/formatting", "params": {"textDocument": {"uri": "untitled:b.txt"}, "options": {}}}`,
+	)
+	suite.Len(replies, 5)
+	suite.Equal("utf-8", replies[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})["positionEncoding"])
+
+	actions := replies[2]["result"].([]interface{})
+	suite.Len(actions, 2)
+	suite.Equal("Fix missing space", actions[0].(map[string]interface{})["title"])
+	suite.JSONEq(`{"changes": {"untitled:a.txt": [
+		{"range": {"start": {"line": 1, "character": 3}, "end": {"line": 1, "character": 3}}, "newText": " "}
+	]}}`, suite.jsonOf(actions[0].(map[string]interface{})["edit"]))
+	suite.Equal("source.fixAll", actions[1].(map[string]interface{})["kind"])
+
+	suite.JSONEq(`[
+		{"range": {"start": {"line": 1, "character": 3}, "end": {"line": 1, "character": 3}}, "newText": " "},
+		{"range": {"start": {"line": 1, "character": 5}, "end": {"line": 1, "character": 5}}, "newText": " "}
+	]`, suite.jsonOf(replies[3]["result"]))
+	suite.True(strings.Contains(suite.jsonOf(replies[4]["error"]), "document not open"))
+}
+
+func (suite *LSPTestSuite) TestLineBreaks() {
+	_, replies := suite.run(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"capabilities": {}}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": "untitled:a.txt", "text": "a\r\n中文abc\r與PM"}}}`,
+	)
+	suite.Len(replies, 2)
+	suite.JSONEq(`{"uri": "untitled:a.txt", "diagnostics": [
+		{"range": {"start": {"line": 1, "character": 1}, "end": {"line": 1, "character": 3}}, "severity": 2, "code": "missing-space", "source": "pangu-axe", "message": "missing space"},
+		{"range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 2}}, "severity": 2, "code": "missing-space", "source": "pangu-axe", "message": "missing space"}
+	]}`, suite.jsonOf(replies[1]["params"]))
+}
+
+func (suite *LSPTestSuite) TestModeError() {
+	_, replies := suite.run(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"capabilities": {}}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": "untitled:a.json", "text": "{\"a\": \"中文abc\""}}}`,
+	)
+	suite.Len(replies, 2)
+	diagnostics := replies[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	suite.Len(diagnostics, 1)
+	suite.Equal(float64(1), diagnostics[0].(map[string]interface{})["severity"])
+	suite.NotEmpty(diagnostics[0].(map[string]interface{})["message"])
+}
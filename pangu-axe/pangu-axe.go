@@ -1,12 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/codegangsta/cli"
 	"github.com/fatih/color"
-	"github.com/vinta/pangu"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const (
@@ -32,7 +35,7 @@ func prefixFilename(path, specified string) string {
 	return newFilename
 }
 
-func processFile(errc chan error, filename, o string) {
+func processFile(errc chan error, filename, o string, s *settings) {
 	var fw *os.File
 	var err error
 
@@ -42,12 +45,27 @@ func processFile(errc chan error, filename, o string) {
 		return
 	}
 
+	if len(o) == 0 {
+		o = s.output
+	}
+
 	switch o {
 	case "stdout", "STDOUT":
 		fw = os.Stdout
 	case "stderr", "STDERR":
 		fw = os.Stderr
+	case "inplace":
+		var buf bytes.Buffer
+		err = spacingFile(s, filename, &buf)
+		if err == nil {
+			err = writeFile(filename, buf.Bytes())
+		}
+		errc <- err
+		return
 	default:
+		if o == "prefix" {
+			o = ""
+		}
 		newFilename := prefixFilename(filename, o)
 		fw, err = os.Create(newFilename)
 		if err != nil {
@@ -57,10 +75,80 @@ func processFile(errc chan error, filename, o string) {
 		defer fw.Close()
 	}
 
-	err = pangu.SpacingFile(filename, fw)
+	err = spacingFile(s, filename, fw)
 	errc <- err
 }
 
+// writeFile replaces the content of filename, keeping its permissions.
+func writeFile(filename string, data []byte) error {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, data, fi.Mode())
+}
+
+// overrides are the settings given on the command line, which win over
+// the ones of config files.
+type overrides struct {
+	columns        string // comma-separated
+	encoding       string
+	outputEncoding string
+}
+
+func overridesOf(c *cli.Context) overrides {
+	return overrides{
+		columns:        c.String("columns"),
+		encoding:       c.String("encoding"),
+		outputEncoding: c.String("output-encoding"),
+	}
+}
+
+// apply returns s with the overrides applied.
+func (o overrides) apply(s *settings) *settings {
+	c := *s
+	if len(o.columns) > 0 {
+		c.csvColumns = strings.Split(o.columns, ",")
+	}
+	if len(o.encoding) > 0 {
+		c.encoding = o.encoding
+	}
+	if len(o.outputEncoding) > 0 {
+		c.outputEncoding = o.outputEncoding
+	}
+
+	return &c
+}
+
+// resolveFiles looks up the settings of each file, applying o, and drops
+// the files filtered out by them.
+func resolveFiles(filenames []string, o overrides) ([]string, map[string]*settings) {
+	for _, name := range []string{o.encoding, o.outputEncoding} {
+		if err := checkEncoding(name); err != nil {
+			color.Red("%s", err)
+			os.Exit(1)
+		}
+	}
+
+	var jobs []string
+	configs := map[string]*settings{}
+	for _, filename := range filenames {
+		s, err := resolveConfig(filepath.Dir(filename))
+		if err != nil {
+			color.Red("%s", err)
+			os.Exit(1)
+		}
+		if s.skip(filename) {
+			continue
+		}
+		jobs = append(jobs, filename)
+		configs[filename] = o.apply(s)
+	}
+
+	return jobs, configs
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Name = NAME
@@ -68,6 +156,19 @@ func main() {
 	app.Version = VERSION
 	app.Author = AUTHOR
 	app.Email = EMAIL
+	app.Flags = []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "dict, d",
+			Usage: "Loads terms that must never be changed from a dictionary file, one term per line",
+		},
+	}
+	app.Before = func(c *cli.Context) error {
+		err := loadDictionaries(c.GlobalStringSlice("dict"))
+		if err != nil {
+			color.Red("%s", err)
+		}
+		return err
+	}
 	app.Commands = []cli.Command{
 		{
 			Name:    "text",
@@ -79,8 +180,14 @@ func main() {
 					return
 				}
 
+				s, err := resolveConfig(".")
+				if err != nil {
+					color.Red("%s", err)
+					os.Exit(1)
+				}
+
 				text := c.Args().First()
-				fmt.Println(pangu.SpacingText(text))
+				fmt.Println(s.spacer.SpacingText(text))
 			},
 		},
 		{
@@ -93,6 +200,21 @@ func main() {
 					Value: "",
 					Usage: fmt.Sprintf(`Specifies the output file name. If not specified, the output file name will be "%sfilename.ext"`, PREFIX),
 				},
+				cli.StringFlag{
+					Name:  "columns",
+					Value: "",
+					Usage: "Specifies the comma-separated CSV or TSV columns to space, by header name. If not specified, every column is spaced",
+				},
+				cli.StringFlag{
+					Name:  "encoding, e",
+					Value: "",
+					Usage: "Specifies the encoding of the files: utf-8, auto, big5, gbk, gb18030, shift_jis, euc-jp or euc-kr. If not specified, utf-8",
+				},
+				cli.StringFlag{
+					Name:  "output-encoding",
+					Value: "",
+					Usage: "Specifies the encoding of the output files. If not specified, the encoding of each file",
+				},
 			},
 			Action: func(c *cli.Context) {
 				if len(c.Args()) == 0 {
@@ -107,13 +229,15 @@ func main() {
 					os.Exit(1)
 				}
 
+				jobs, configs := resolveFiles(c.Args(), overridesOf(c))
+
 				errc := make(chan error)
 
-				for _, filename := range c.Args() {
-					go processFile(errc, filename, o)
+				for _, filename := range jobs {
+					go processFile(errc, filename, o, configs[filename])
 				}
 
-				for _ = range c.Args() {
+				for _ = range jobs {
 					err := <-errc
 					if err != nil {
 						color.Red("%s", err)
@@ -121,6 +245,248 @@ func main() {
 				}
 			},
 		},
+		{
+			Name:  "epub",
+			Usage: "Performs paranoid text spacing on the content documents of EPUB e-books",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Value: "",
+					Usage: fmt.Sprintf(`Specifies the output file name. If not specified, the output file name will be "%sfilename.epub"`, PREFIX),
+				},
+			},
+			Action: func(c *cli.Context) {
+				if len(c.Args()) == 0 {
+					cli.ShowSubcommandHelp(c)
+					return
+				}
+
+				o := c.String("output")
+
+				if len(c.Args()) > 1 && len(o) > 0 {
+					color.Red(`can't use the "-output" flag with multiple files`)
+					os.Exit(1)
+				}
+
+				failed := false
+				for _, filename := range c.Args() {
+					s, err := resolveConfig(filepath.Dir(filename))
+					if err == nil {
+						err = processEPUB(filename, o, s)
+					}
+					if err != nil {
+						color.Red("%s: %s", filename, err)
+						failed = true
+					}
+				}
+
+				if failed {
+					os.Exit(1)
+				}
+			},
+		},
+		{
+			Name:  "serve",
+			Usage: "Serves paranoid text spacing over HTTP",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "addr",
+					Value: ":8080",
+					Usage: "Specifies the TCP address to listen on",
+				},
+				cli.Int64Flag{
+					Name:  "max-bytes",
+					Value: 1 << 20,
+					Usage: "Specifies the largest request body accepted, in bytes",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 10 * time.Second,
+					Usage: "Specifies how long a request may take",
+				},
+			},
+			Action: func(c *cli.Context) {
+				s, err := resolveConfig(".")
+				if err != nil {
+					color.Red("%s", err)
+					os.Exit(1)
+				}
+
+				addr := c.String("addr")
+				fmt.Printf("Listening on %s\n", addr)
+				err = serve(addr, s, c.Int64("max-bytes"), c.Duration("timeout"))
+				color.Red("%s", err)
+				os.Exit(1)
+			},
+		},
+		{
+			Name:  "lsp",
+			Usage: "Runs a Language Server Protocol server over stdin and stdout, for editors",
+			Action: func(c *cli.Context) {
+				code, err := runLSP(os.Stdin, os.Stdout)
+				if err != nil {
+					// Not in color, since stdout belongs to the client.
+					fmt.Fprintln(os.Stderr, err)
+				}
+				os.Exit(code)
+			},
+		},
+		{
+			Name:  "staged",
+			Usage: "Checks or fixes the content of the files staged in git, for pre-commit hooks",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "fix",
+					Usage: "Fixes the problems in the staged content, and in the files unless they have unstaged changes",
+				},
+				cli.BoolFlag{
+					Name:  "changed-lines",
+					Usage: "Only checks or fixes the lines changed since HEAD",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Specifies the report format: text, json, sarif, checkstyle or github",
+				},
+				cli.StringFlag{
+					Name:  "encoding, e",
+					Value: "",
+					Usage: "Specifies the encoding of the files: utf-8, auto, big5, gbk, gb18030, shift_jis, euc-jp or euc-kr. If not specified, utf-8",
+				},
+			},
+			Action: func(c *cli.Context) {
+				format := c.String("format")
+				report, ok := reporters[format]
+				if !ok {
+					color.Red("unknown format %q", format)
+					os.Exit(1)
+				}
+
+				// Errors go to stderr, to keep stdout readable by machines.
+				top, err := gitTop(".")
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				files, err := stagedFiles(top)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+
+				err = checkStaged(top, files, overridesOf(c), c.Bool("fix"), c.Bool("changed-lines"), report)
+				if err != nil && err != errProblems {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				if err != nil {
+					os.Exit(1)
+				}
+			},
+		},
+		{
+			Name:  "git-hook",
+			Usage: "Manages the git pre-commit hook running the staged command",
+			Subcommands: []cli.Command{
+				{
+					Name:  "install",
+					Usage: "Installs a pre-commit hook running the staged command in the current repository",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "fix",
+							Usage: "Fixes staged files instead of rejecting commits with problems",
+						},
+						cli.BoolFlag{
+							Name:  "changed-lines",
+							Usage: "Only checks or fixes the lines changed since HEAD",
+						},
+						cli.BoolFlag{
+							Name:  "force, f",
+							Usage: "Replaces a pre-commit hook that was not installed by pangu-axe",
+						},
+					},
+					Action: func(c *cli.Context) {
+						var args []string
+						for _, flag := range []string{"fix", "changed-lines"} {
+							if c.Bool(flag) {
+								args = append(args, "--"+flag)
+							}
+						}
+
+						filename, err := installHook(".", c.Bool("force"), args...)
+						if err != nil {
+							color.Red("%s", err)
+							os.Exit(1)
+						}
+						fmt.Printf("Installed %s\n", filename)
+					},
+				},
+			},
+		},
+		{
+			Name:    "check",
+			Usage:   "Reports where paranoid text spacing would change files, without changing them",
+			Aliases: []string{"c"},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "columns",
+					Value: "",
+					Usage: "Specifies the comma-separated CSV or TSV columns to space, by header name. If not specified, every column is spaced",
+				},
+				cli.StringFlag{
+					Name:  "encoding, e",
+					Value: "",
+					Usage: "Specifies the encoding of the files: utf-8, auto, big5, gbk, gb18030, shift_jis, euc-jp or euc-kr. If not specified, utf-8",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Specifies the report format: text, json, sarif, checkstyle or github",
+				},
+			},
+			Action: func(c *cli.Context) {
+				if len(c.Args()) == 0 {
+					cli.ShowSubcommandHelp(c)
+					return
+				}
+
+				format := c.String("format")
+				report, ok := reporters[format]
+				if !ok {
+					color.Red("unknown format %q", format)
+					os.Exit(1)
+				}
+
+				jobs, configs := resolveFiles(c.Args(), overridesOf(c))
+
+				failed := false
+				var findings []finding
+				for _, filename := range jobs {
+					problems, err := checkFile(configs[filename], filename)
+					if err != nil {
+						if format == "text" {
+							color.Red("%s", err)
+						} else {
+							// Keeps stdout readable by machines.
+							fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+						}
+						failed = true
+						continue
+					}
+					for _, p := range problems {
+						findings = append(findings, finding{filename, p})
+					}
+				}
+
+				if err := report(os.Stdout, findings); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					failed = true
+				}
+
+				if failed || len(findings) > 0 {
+					os.Exit(1)
+				}
+			},
+		},
 	}
 
 	app.Run(os.Args)
@@ -53,3 +53,10 @@ func (suite *PanguAxeTestSuite) TestFileCmd() {
 
 	suite.Equal("", suite.getOutput())
 }
+
+func (suite *PanguAxeTestSuite) TestCheckCmd() {
+	os.Args = []string{NAME, "check", "../_fixtures/test_file.expected.txt"}
+	main()
+
+	suite.Equal("", suite.getOutput())
+}
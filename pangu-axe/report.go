@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/vinta/pangu"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// A finding is a problem found in a file by the check command.
+type finding struct {
+	filename string
+	pangu.Problem
+}
+
+// A reporter writes the findings of the check command in some format.
+type reporter func(w io.Writer, findings []finding) error
+
+// reporters maps the values of the --format flag of the check command to
+// their reporters.
+var reporters = map[string]reporter{
+	"checkstyle": reportCheckstyle,
+	"github":     reportGitHub,
+	"json":       reportJSON,
+	"sarif":      reportSARIF,
+	"text":       reportText,
+}
+
+// checkRules describes the rules of the problems found by the check
+// command.
+var checkRules = []struct {
+	id          string
+	description string
+}{
+	{"missing-space", "A space is missing between CJK and half-width characters."},
+	{"extra-space", "A space is not wanted between CJK and half-width characters."},
+	{"wrong-space", "Whitespace should be a single space."},
+	{"unused-directive", "A suppression directive did not suppress anything."},
+}
+
+func reportText(w io.Writer, findings []finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%s\n", f.filename, f.Problem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportJSON writes the findings as an array of objects, with the fix of
+// each one, if any, in the same line.
+func reportJSON(w io.Writer, findings []finding) error {
+	type jsonFix struct {
+		Line      int    `json:"line"`
+		Column    int    `json:"column"`
+		EndLine   int    `json:"endLine"`
+		EndColumn int    `json:"endColumn"`
+		Text      string `json:"text"`
+	}
+	type jsonFinding struct {
+		File    string   `json:"file"`
+		Line    int      `json:"line"`
+		Column  int      `json:"column"`
+		Rule    string   `json:"rule"`
+		Message string   `json:"message"`
+		Fix     *jsonFix `json:"fix,omitempty"`
+	}
+
+	out := []jsonFinding{}
+	for _, f := range findings {
+		jf := jsonFinding{
+			File:    f.filename,
+			Line:    f.Line,
+			Column:  f.Column,
+			Rule:    f.Rule,
+			Message: f.Message,
+		}
+		if f.Fix != nil {
+			jf.Fix = &jsonFix{f.Line, f.Column, f.EndLine, f.EndColumn, f.Fix.New}
+		}
+		out = append(out, jf)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+// reportSARIF writes the findings as a SARIF 2.1.0 log, for code scanning
+// services.
+func reportSARIF(w io.Writer, findings []finding) error {
+	var rules []interface{}
+	index := map[string]int{}
+	for i, r := range checkRules {
+		index[r.id] = i
+		rules = append(rules, map[string]interface{}{
+			"id":               r.id,
+			"shortDescription": map[string]string{"text": r.description},
+		})
+	}
+
+	results := []interface{}{}
+	for _, f := range findings {
+		artifact := map[string]string{"uri": filepath.ToSlash(f.filename)}
+		result := map[string]interface{}{
+			"ruleId":    f.Rule,
+			"ruleIndex": index[f.Rule],
+			"level":     "warning",
+			"message":   map[string]string{"text": f.Message},
+			"locations": []interface{}{map[string]interface{}{
+				"physicalLocation": map[string]interface{}{
+					"artifactLocation": artifact,
+					"region":           map[string]int{"startLine": f.Line, "startColumn": f.Column},
+				},
+			}},
+		}
+		if f.Fix != nil {
+			result["fixes"] = []interface{}{map[string]interface{}{
+				"description": map[string]string{"text": "Fix " + f.Message},
+				"artifactChanges": []interface{}{map[string]interface{}{
+					"artifactLocation": artifact,
+					"replacements": []interface{}{map[string]interface{}{
+						"deletedRegion": map[string]int{
+							"startLine":   f.Line,
+							"startColumn": f.Column,
+							"endLine":     f.EndLine,
+							"endColumn":   f.EndColumn,
+						},
+						"insertedContent": map[string]string{"text": f.Fix.New},
+					}},
+				}},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool": map[string]interface{}{
+				"driver": map[string]interface{}{
+					"name":           NAME,
+					"version":        VERSION,
+					"informationUri": "https://github.com/vinta/pangu",
+					"rules":          rules,
+				},
+			},
+			"columnKind": "unicodeCodePoints",
+			"results":    results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(log)
+}
+
+// reportCheckstyle writes the findings as a Checkstyle XML report.
+func reportCheckstyle(w io.Writer, findings []finding) error {
+	type checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+	type checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+	report := struct {
+		XMLName xml.Name          `xml:"checkstyle"`
+		Version string            `xml:"version,attr"`
+		Files   []*checkstyleFile `xml:"file"`
+	}{Version: "4.3"}
+
+	files := map[string]*checkstyleFile{}
+	for _, f := range findings {
+		file, ok := files[f.filename]
+		if !ok {
+			file = &checkstyleFile{Name: f.filename}
+			files[f.filename] = file
+			report.Files = append(report.Files, file)
+		}
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     f.Line,
+			Column:   f.Column,
+			Severity: "warning",
+			Message:  f.Message,
+			Source:   NAME + "." + f.Rule,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// githubEscaper escapes the values of workflow commands, and githubProperty
+// their properties as well.
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+var githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// reportGitHub writes the findings as GitHub Actions workflow commands,
+// which annotate the files of pull requests.
+func reportGitHub(w io.Writer, findings []finding) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintf(w, "::warning file=%s,line=%d,col=%d,title=%s::%s\n",
+			githubProperty.Replace(filepath.ToSlash(f.filename)),
+			f.Line,
+			f.Column,
+			githubProperty.Replace(NAME+" "+f.Rule),
+			githubEscaper.Replace(f.Message))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type ReportTestSuite struct {
+	suite.Suite
+	findings []finding
+}
+
+func TestReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+func (suite *ReportTestSuite) SetupTest() {
+	suite.findings = []finding{
+		{"docs/a,b.txt", pangu.Problem{Line: 1, Column: 2, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 3, End: 3, New: " "}, EndLine: 1, EndColumn: 2}},
+		{"docs/a,b.txt", pangu.Problem{Line: 3, Column: 5, Message: "extra space", Rule: "extra-space", Fix: &pangu.Edit{Start: 20, End: 22, New: ""}, EndLine: 3, EndColumn: 7}},
+		{"b.txt", pangu.Problem{Line: 8, Column: 1, Message: "unused pangu-disable", Rule: "unused-directive"}},
+	}
+}
+
+func (suite *ReportTestSuite) report(format string) string {
+	var buf bytes.Buffer
+	suite.Nil(reporters[format](&buf, suite.findings))
+
+	return buf.String()
+}
+
+func (suite *ReportTestSuite) TestText() {
+	suite.Equal("docs/a,b.txt:1:2: missing space\ndocs/a,b.txt:3:5: extra space\nb.txt:8:1: unused pangu-disable\n", suite.report("text"))
+}
+
+func (suite *ReportTestSuite) TestJSON() {
+	suite.JSONEq(`[
+		{"file": "docs/a,b.txt", "line": 1, "column": 2, "rule": "missing-space", "message": "missing space", "fix": {"line": 1, "column": 2, "endLine": 1, "endColumn": 2, "text": " "}},
+		{"file": "docs/a,b.txt", "line": 3, "column": 5, "rule": "extra-space", "message": "extra space", "fix": {"line": 3, "column": 5, "endLine": 3, "endColumn": 7, "text": ""}},
+		{"file": "b.txt", "line": 8, "column": 1, "rule": "unused-directive", "message": "unused pangu-disable"}
+	]`, suite.report("json"))
+
+	suite.findings = nil
+	suite.Equal("[]\n", suite.report("json"))
+}
+
+func (suite *ReportTestSuite) TestSARIF() {
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string                   `json:"name"`
+					Rules []map[string]interface{} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []map[string]interface{} `json:"results"`
+		} `json:"runs"`
+	}
+	suite.Nil(json.Unmarshal([]byte(suite.report("sarif")), &log))
+	suite.Equal("2.1.0", log.Version)
+	run := log.Runs[0]
+	suite.Equal(NAME, run.Tool.Driver.Name)
+	suite.Len(run.Tool.Driver.Rules, 4)
+	suite.Len(run.Results, 3)
+
+	data, err := json.Marshal(run.Results[1])
+	suite.Nil(err)
+	suite.JSONEq(`{
+		"ruleId": "extra-space",
+		"ruleIndex": 1,
+		"level": "warning",
+		"message": {"text": "extra space"},
+		"locations": [{"physicalLocation": {"artifactLocation": {"uri": "docs/a,b.txt"}, "region": {"startLine": 3, "startColumn": 5}}}],
+		"fixes": [{
+			"description": {"text": "Fix extra space"},
+			"artifactChanges": [{
+				"artifactLocation": {"uri": "docs/a,b.txt"},
+				"replacements": [{"deletedRegion": {"startLine": 3, "startColumn": 5, "endLine": 3, "endColumn": 7}, "insertedContent": {"text": ""}}]
+			}]
+		}]
+	}`, string(data))
+	suite.NotContains(run.Results[2], "fixes")
+}
+
+func (suite *ReportTestSuite) TestCheckstyle() {
+	suite.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="docs/a,b.txt">
+    <error line="1" column="2" severity="warning" message="missing space" source="pangu-axe.missing-space"></error>
+    <error line="3" column="5" severity="warning" message="extra space" source="pangu-axe.extra-space"></error>
+  </file>
+  <file name="b.txt">
+    <error line="8" column="1" severity="warning" message="unused pangu-disable" source="pangu-axe.unused-directive"></error>
+  </file>
+</checkstyle>
+`, suite.report("checkstyle"))
+}
+
+func (suite *ReportTestSuite) TestGitHub() {
+	suite.Equal("::warning file=docs/a%2Cb.txt,line=1,col=2,title=pangu-axe missing-space::missing space\n"+
+		"::warning file=docs/a%2Cb.txt,line=3,col=5,title=pangu-axe extra-space::extra space\n"+
+		"::warning file=b.txt,line=8,col=1,title=pangu-axe unused-directive::unused pangu-disable\n", suite.report("github"))
+}
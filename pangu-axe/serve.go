@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/vinta/pangu"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// apiModes maps the spacing endpoints of the HTTP API to their modes.
+var apiModes = map[string]func(s *pangu.Spacer, text string) (string, error){
+	"html": func(s *pangu.Spacer, text string) (string, error) {
+		out, err := s.SpacingHTML([]byte(text))
+		return string(out), err
+	},
+	"markdown": func(s *pangu.Spacer, text string) (string, error) {
+		return string(s.SpacingMarkdown([]byte(text))), nil
+	},
+	"text": func(s *pangu.Spacer, text string) (string, error) {
+		return string(s.SpacingDocument([]byte(text))), nil
+	},
+}
+
+// contentTypes are the types of raw responses, by mode.
+var contentTypes = map[string]string{
+	"html":     "text/html; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"text":     "text/plain; charset=utf-8",
+}
+
+// apiText is the JSON body of a spacing request or response.
+type apiText struct {
+	Mode  string `json:"mode,omitempty"` // only in batch requests
+	Text  string `json:"text"`
+	Error string `json:"error,omitempty"` // only in batch responses
+}
+
+// apiBatch is the JSON body of a batch request or response.
+type apiBatch struct {
+	Requests []apiText `json:"requests,omitempty"`
+	Results  []apiText `json:"results,omitempty"`
+}
+
+// server serves the HTTP API of the serve command.
+type server struct {
+	settings *settings
+	maxBytes int64
+	mux      *http.ServeMux
+
+	mu       sync.Mutex
+	requests map[[2]string]int64 // by path and status code
+	bytes    map[string]int64    // read, by path
+	seconds  map[string]float64  // spent, by path
+}
+
+// newServer returns the handler of the HTTP API. Request bodies larger
+// than maxBytes are refused, and requests taking longer than timeout are
+// answered with 503 Service Unavailable.
+func newServer(s *settings, maxBytes int64, timeout time.Duration) http.Handler {
+	srv := &server{
+		settings: s,
+		maxBytes: maxBytes,
+		mux:      http.NewServeMux(),
+		requests: map[[2]string]int64{},
+		bytes:    map[string]int64{},
+		seconds:  map[string]float64{},
+	}
+	for mode := range apiModes {
+		srv.handle("/"+mode, srv.spacing(mode))
+	}
+	srv.handle("/batch", srv.batch)
+	srv.handle("/healthz", srv.healthz)
+	srv.mux.HandleFunc("/metrics", srv.metrics)
+
+	return http.TimeoutHandler(srv.mux, timeout, "request timed out\n")
+}
+
+// serve listens on addr and serves the HTTP API until it fails.
+func serve(addr string, s *settings, maxBytes int64, timeout time.Duration) error {
+	hs := &http.Server{
+		Addr:         addr,
+		Handler:      newServer(s, maxBytes, timeout),
+		ReadTimeout:  timeout,
+		WriteTimeout: 2 * timeout, // leaves time to answer timed out requests
+	}
+
+	return hs.ListenAndServe()
+}
+
+// statusWriter records the status code written to a ResponseWriter.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// handle registers h for path, counting its requests in the metrics.
+func (srv *server) handle(path string, h http.HandlerFunc) {
+	srv.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{w, http.StatusOK}
+		h(sw, r)
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		srv.requests[[2]string{path, fmt.Sprint(sw.code)}]++
+		srv.seconds[path] += time.Since(start).Seconds()
+	})
+}
+
+// read returns the body of r, once checked against the method, the size
+// limit and the encoding, or writes an error and returns false.
+func (srv *server) read(w http.ResponseWriter, r *http.Request, isJSON bool) ([]byte, bool) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		writeError(w, isJSON, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, srv.maxBytes+1))
+	srv.mu.Lock()
+	srv.bytes[r.URL.Path] += int64(len(body))
+	srv.mu.Unlock()
+	switch {
+	case err != nil:
+		writeError(w, isJSON, http.StatusBadRequest, err.Error())
+		return nil, false
+	case int64(len(body)) > srv.maxBytes:
+		writeError(w, isJSON, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", srv.maxBytes))
+		return nil, false
+	case !utf8.Valid(body):
+		writeError(w, isJSON, http.StatusBadRequest, "request body is not valid UTF-8")
+		return nil, false
+	}
+
+	return body, true
+}
+
+// spacing returns the handler of the endpoint of mode, which takes either
+// a raw body or a JSON {"text": "..."} object, and answers in kind.
+func (srv *server) spacing(mode string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		isJSON := isJSON(r)
+		body, ok := srv.read(w, r, isJSON)
+		if !ok {
+			return
+		}
+
+		if !isJSON {
+			out, err := apiModes[mode](srv.settings.spacer, string(body))
+			if err != nil {
+				writeError(w, false, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			w.Header().Set("Content-Type", contentTypes[mode])
+			io.WriteString(w, out)
+			return
+		}
+
+		var req apiText
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, true, http.StatusBadRequest, err.Error())
+			return
+		}
+		out, err := apiModes[mode](srv.settings.spacer, req.Text)
+		if err != nil {
+			writeError(w, true, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, apiText{Text: out})
+	}
+}
+
+// batch handles a JSON {"requests": [{"mode": "html", "text": "..."}]}
+// object, answering with the results in the same order. The mode defaults
+// to "text", and a request that fails has an error instead of a text.
+func (srv *server) batch(w http.ResponseWriter, r *http.Request) {
+	body, ok := srv.read(w, r, true)
+	if !ok {
+		return
+	}
+
+	var req apiBatch
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, true, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := apiBatch{Results: make([]apiText, len(req.Requests))}
+	for i, t := range req.Requests {
+		if t.Mode == "" {
+			t.Mode = "text"
+		}
+		fn, ok := apiModes[t.Mode]
+		if !ok {
+			resp.Results[i].Error = fmt.Sprintf("unknown mode %q", t.Mode)
+			continue
+		}
+		out, err := fn(srv.settings.spacer, t.Text)
+		if err != nil {
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		resp.Results[i].Text = out
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (srv *server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
+
+// metrics writes the counters of the server in the Prometheus text format.
+func (srv *server) metrics(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	keys := make([][2]string, 0, len(srv.requests))
+	for key := range srv.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0]+" "+keys[i][1] < keys[j][0]+" "+keys[j][1]
+	})
+	fmt.Fprintln(w, "# HELP pangu_requests_total Requests handled, by path and status code.")
+	fmt.Fprintln(w, "# TYPE pangu_requests_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "pangu_requests_total{path=%q,code=%q} %d\n", key[0], key[1], srv.requests[key])
+	}
+
+	paths := make([]string, 0, len(srv.seconds))
+	for path := range srv.seconds {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintln(w, "# HELP pangu_request_bytes_total Bytes of request bodies read, by path.")
+	fmt.Fprintln(w, "# TYPE pangu_request_bytes_total counter")
+	for _, path := range paths {
+		fmt.Fprintf(w, "pangu_request_bytes_total{path=%q} %d\n", path, srv.bytes[path])
+	}
+	fmt.Fprintln(w, "# HELP pangu_request_seconds_total Time spent handling requests, by path.")
+	fmt.Fprintln(w, "# TYPE pangu_request_seconds_total counter")
+	for _, path := range paths {
+		fmt.Fprintf(w, "pangu_request_seconds_total{path=%q} %g\n", path, srv.seconds[path])
+	}
+}
+
+// isJSON reports whether the body of r is JSON.
+func isJSON(r *http.Request) bool {
+	t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return t == "application/json"
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// writeError writes msg as a JSON {"error": "..."} object if isJSON, and
+// as plain text otherwise.
+func writeError(w http.ResponseWriter, isJSON bool, code int, msg string) {
+	if !isJSON {
+		http.Error(w, msg, code)
+		return
+	}
+	writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{msg})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ServeTestSuite struct {
+	suite.Suite
+	handler http.Handler
+}
+
+func TestServeTestSuite(t *testing.T) {
+	suite.Run(t, new(ServeTestSuite))
+}
+
+func (suite *ServeTestSuite) SetupTest() {
+	suite.handler = newServer(&settings{spacer: pangu.NewSpacer()}, 256, time.Second)
+}
+
+func (suite *ServeTestSuite) do(method, path, contentType, body string) (int, string, string) {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	suite.handler.ServeHTTP(w, r)
+	out, err := ioutil.ReadAll(w.Body)
+	suite.Nil(err)
+
+	return w.Code, w.Header().Get("Content-Type"), string(out)
+}
+
+func (suite *ServeTestSuite) TestRaw() {
+	code, contentType, body := suite.do("POST", "/text", "", "與PM戰鬥的人")
+	suite.Equal(200, code)
+	suite.Equal("text/plain; charset=utf-8", contentType)
+	suite.Equal("與 PM 戰鬥的人", body)
+
+	code, contentType, body = suite.do("POST", "/html", "text/html", "<p>當你凝視著<b>bug</b></p>")
+	suite.Equal(200, code)
+	suite.Equal("text/html; charset=utf-8", contentType)
+	suite.Equal("<p>當你凝視著 <b>bug</b></p>", body)
+
+	code, contentType, body = suite.do("POST", "/markdown", "application/octet-stream", "使用`go test`指令\n```\n中文abc\n```\n")
+	suite.Equal(200, code)
+	suite.Equal("text/markdown; charset=utf-8", contentType)
+	suite.Equal("使用 `go test` 指令\n```\n中文abc\n```\n", body)
+}
+
+func (suite *ServeTestSuite) TestJSON() {
+	code, contentType, body := suite.do("POST", "/text", "application/json", `{"text": "與PM戰鬥的人"}`)
+	suite.Equal(200, code)
+	suite.Equal("application/json; charset=utf-8", contentType)
+	suite.JSONEq(`{"text": "與 PM 戰鬥的人"}`, body)
+
+	code, _, body = suite.do("POST", "/text", "application/json", `{"text": `)
+	suite.Equal(400, code)
+	suite.Contains(body, `"error"`)
+}
+
+func (suite *ServeTestSuite) TestBatch() {
+	code, _, body := suite.do("POST", "/batch", "application/json", `{"requests": [
+		{"text": "與PM戰鬥的人"},
+		{"mode": "html", "text": "<p>當你凝視著<b>bug</b></p>"},
+		{"mode": "pdf", "text": "中文abc"}
+	]}`)
+	suite.Equal(200, code)
+	suite.JSONEq(`{"results": [
+		{"text": "與 PM 戰鬥的人"},
+		{"text": "<p>當你凝視著 <b>bug</b></p>"},
+		{"text": "", "error": "unknown mode \"pdf\""}
+	]}`, body)
+}
+
+func (suite *ServeTestSuite) TestErrors() {
+	code, _, _ := suite.do("GET", "/text", "", "")
+	suite.Equal(405, code)
+
+	code, _, _ = suite.do("POST", "/text", "", strings.Repeat("中", 100))
+	suite.Equal(413, code)
+
+	code, _, _ = suite.do("POST", "/text", "", "\xff")
+	suite.Equal(400, code)
+}
+
+func (suite *ServeTestSuite) TestHealthAndMetrics() {
+	code, _, body := suite.do("GET", "/healthz", "", "")
+	suite.Equal(200, code)
+	suite.Equal("ok\n", body)
+
+	suite.do("POST", "/text", "", "中文abc")
+	suite.do("GET", "/text", "", "")
+	code, _, body = suite.do("GET", "/metrics", "", "")
+	suite.Equal(200, code)
+	suite.Contains(body, `pangu_requests_total{path="/text",code="200"} 1`)
+	suite.Contains(body, `pangu_requests_total{path="/text",code="405"} 1`)
+	suite.Contains(body, `pangu_request_bytes_total{path="/text"} 9`)
+}
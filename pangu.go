@@ -1,11 +1,8 @@
 package pangu
 
 import (
-	"bufio"
 	"bytes"
-	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"text/template"
 )
@@ -81,24 +78,57 @@ func re(exp string) string {
 	return expr
 }
 
-// SpacingText performs paranoid text spacing on text.
-// It returns the processed text, with love.
-func SpacingText(text string) string {
-	if len(text) < 2 {
-		return text
+// A rule is a named step of the paranoid text spacing algorithm.
+// Rules run in the order they are listed.
+type rule struct {
+	name string
+	fn   func(text string) string
+}
+
+var rules = []rule{
+	{"quote", spacingQuote},
+	{"hash", spacingHash},
+	{"operator", spacingOperator},
+	{"bracket", spacingBracket},
+	{"symbol", spacingSymbol},
+	{"ans", spacingANS},
+}
+
+// Rules returns the names of all spacing rules, in the order they run.
+// The names are accepted by Spacer.Enable and Spacer.Disable.
+func Rules() []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.name
 	}
 
+	return names
+}
+
+func spacingQuote(text string) string {
 	text = cjk_quote.ReplaceAllString(text, "$1 $2")
 	text = quote_cjk.ReplaceAllString(text, "$1 $2")
 	text = fix_quote.ReplaceAllString(text, "$1$3$5")
 	text = fix_single_quote.ReplaceAllString(text, "$1$3$4")
 
+	return text
+}
+
+func spacingHash(text string) string {
 	text = cjk_hash.ReplaceAllString(text, "$1 $2")
 	text = hash_cjk.ReplaceAllString(text, "$1 $3")
 
+	return text
+}
+
+func spacingOperator(text string) string {
 	text = cjk_operator_ans.ReplaceAllString(text, "$1 $2 $3")
 	text = ans_operator_cjk.ReplaceAllString(text, "$1 $2 $3")
 
+	return text
+}
+
+func spacingBracket(text string) string {
 	oldText := text
 	newText := cjk_bracket_cjk.ReplaceAllString(oldText, "$1 $2 $4")
 	text = newText
@@ -108,40 +138,29 @@ func SpacingText(text string) string {
 	}
 	text = fix_bracket.ReplaceAllString(text, "$1$3$5")
 
-	text = fix_symbol.ReplaceAllString(text, "$1$2 $3")
+	return text
+}
+
+func spacingSymbol(text string) string {
+	return fix_symbol.ReplaceAllString(text, "$1$2 $3")
+}
 
+func spacingANS(text string) string {
 	text = cjk_ans.ReplaceAllString(text, "$1 $2")
 	text = ans_cjk.ReplaceAllString(text, "$1 $2")
 
 	return text
 }
 
+// SpacingText performs paranoid text spacing on text.
+// It returns the processed text, with love.
+func SpacingText(text string) string {
+	return defaultSpacer.SpacingText(text)
+}
+
 // SpacingFile reads the file named by filename, performs paranoid text
 // spacing on its contents and writes the processed content to w.
 // A successful call returns err == nil.
 func SpacingFile(filename string, w io.Writer) (err error) {
-	fr, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer fr.Close()
-
-	br := bufio.NewReader(fr)
-	bw := bufio.NewWriter(w)
-
-	for {
-		line, err := br.ReadString('\n')
-		if err == nil {
-			fmt.Fprint(bw, SpacingText(line))
-		} else {
-			if err == io.EOF {
-				fmt.Fprint(bw, SpacingText(line))
-				break
-			}
-			return err
-		}
-	}
-	defer bw.Flush()
-
-	return nil
+	return defaultSpacer.SpacingFile(filename, w)
 }
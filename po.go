@@ -0,0 +1,89 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+var poKeyword = regexp.MustCompile(`^(msgctxt|msgid_plural|msgid|msgstr(?:\[\d+\])?)[ \t]`)
+
+// placeholder matches printf-style format specifiers such as %s, %1$d,
+// %@ or %(name)s, and named placeholders such as {name} or {0}.
+var placeholder = regexp.MustCompile(`%(?:\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|h|ll|l|L|j|z|t)?[diouxXeEfFgGaAcspn@%]|%\([^)]+\)[-+#0]*\d*(?:\.\d+)?[a-zA-Z]|\{[A-Za-z0-9_]*\}`)
+
+// SpacingPO performs paranoid text spacing on a gettext PO or POT
+// catalog. Only msgstr values, including plural forms, are changed;
+// msgid, msgctxt, comments and the header entry are left alone, as are
+// escape sequences and placeholders such as %s, %1$d or {name}. The
+// layout of the file is kept, line breaks between the strings of a
+// value included.
+func (s *Spacer) SpacingPO(src []byte) []byte {
+	var out strings.Builder
+	lines := splitLines(string(src))
+	ctxt, header := false, false
+	for i := 0; i < len(lines); {
+		m := poKeyword.FindStringSubmatch(strings.TrimLeft(lines[i], " \t"))
+		if m == nil {
+			if strings.TrimSpace(lines[i]) == "" {
+				ctxt = false
+			}
+			out.WriteString(lines[i])
+			i++
+			continue
+		}
+
+		// A value is made of the keyword line and the string lines after it.
+		j := i + 1
+		for j < len(lines) && strings.HasPrefix(strings.TrimLeft(lines[j], " \t"), `"`) {
+			j++
+		}
+		field := lines[i:j]
+		i = j
+
+		switch keyword := m[1]; {
+		case keyword == "msgctxt":
+			ctxt = true
+		case keyword == "msgid":
+			header = !ctxt && poValue(field) == ""
+		case strings.HasPrefix(keyword, "msgstr") && !header:
+			out.WriteString(s.spacingPOField(field))
+			continue
+		}
+		for _, line := range field {
+			out.WriteString(line)
+		}
+	}
+
+	return s.suppressed(src, []byte(out.String()), hashDirective)
+}
+
+// poValue returns the raw content of the strings of a value, joined.
+func poValue(field []string) string {
+	var value strings.Builder
+	for _, line := range field {
+		first, last := strings.IndexByte(line, '"'), strings.LastIndexByte(line, '"')
+		if first >= 0 && last > first {
+			value.WriteString(line[first+1 : last])
+		}
+	}
+
+	return value.String()
+}
+
+// spacingPOField performs spacing on the strings of a value as a whole,
+// leaving the keyword, the quotes and the line breaks alone.
+func (s *Spacer) spacingPOField(field []string) string {
+	var segs []segment
+	for _, line := range field {
+		first, last := strings.IndexByte(line, '"'), strings.LastIndexByte(line, '"')
+		if first < 0 || last <= first {
+			segs = append(segs, opaque(line, ""))
+			continue
+		}
+		segs = append(segs, opaque(line[:first+1], ""))
+		segs = append(segs, escapedSegments(line[first+1:last], placeholder)...)
+		segs = append(segs, opaque(line[last:], ""))
+	}
+
+	return s.spacingSegments(segs)
+}
@@ -0,0 +1,35 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"testing"
+)
+
+type POTestSuite struct {
+	suite.Suite
+}
+
+func TestPOTestSuite(t *testing.T) {
+	suite.Run(t, new(POTestSuite))
+}
+
+func (suite *POTestSuite) TestSpacingPO() {
+	src, err := ioutil.ReadFile("_fixtures/test_file.po")
+	checkError(err)
+	expected, err := ioutil.ReadFile("_fixtures/test_file.expected.po")
+	checkError(err)
+
+	suite.Equal(string(expected), string(pangu.NewSpacer().SpacingPO(src)))
+}
+
+func (suite *POTestSuite) TestAcrossStrings() {
+	src := "msgid \"a\"\nmsgstr \"\"\n\"與PM\"\n\"戰鬥的人\"\n"
+	suite.Equal("msgid \"a\"\nmsgstr \"\"\n\"與 PM\"\n\" 戰鬥的人\"\n", string(pangu.NewSpacer().SpacingPO([]byte(src))))
+}
+
+func (suite *POTestSuite) TestEscapes() {
+	src := "msgid \"a\"\nmsgstr \"他說\\\"PM\\\"是\\t敵人\"\n"
+	suite.Equal("msgid \"a\"\nmsgstr \"他說 \\\"PM\\\" 是\\t敵人\"\n", string(pangu.NewSpacer().SpacingPO([]byte(src))))
+}
@@ -0,0 +1,167 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// rstExplicit matches the start of an explicit markup block: a
+// directive, a comment, a hyperlink target, including the anonymous "__"
+// ones, or a substitution definition, with the name of the directive, if
+// any.
+var rstExplicit = regexp.MustCompile(`^(?:\.\.(?:[ \t]+(?:\|[^|]+\|[ \t]+)?([A-Za-z0-9_.:+-]+)::|[ \t]|$)|__(?:[ \t]|$))`)
+
+// rstOption matches the name of a field in a field list, such as the
+// options of a directive, leaving its body after the match.
+var rstOption = regexp.MustCompile(`^:[^:\s][^:]*:(?:[ \t]+|$)`)
+
+// rstTable matches the borders of grid and simple tables.
+var rstTable = regexp.MustCompile(`^(?:\+[-=+]+\+|=+(?:[ \t]+=+)+)[ \t]*$`)
+
+// rstInline matches inline literals, roles, interpreted text, hyperlink
+// references, substitution and footnote references, URLs and backslash
+// escapes.
+var rstInline = regexp.MustCompile("``[^`]+``|\\\\.|(?::[A-Za-z0-9_.+-]+:)?`[^`]+`(?::[A-Za-z0-9_.+-]+:|__?)?|\\|[^|\\s][^|]*\\|_{0,2}|\\[[^\\]\\s]+\\]_|[A-Za-z0-9][A-Za-z0-9_.-]*__?\\b|https?://[^\\s<>]+")
+
+// rstLiteralDirectives are the directives whose content is never changed.
+var rstLiteralDirectives = map[string]bool{
+	"code": true, "code-block": true, "csv-table": true, "graphviz": true,
+	"highlight": true, "list-table": true, "literalinclude": true,
+	"math": true, "parsed-literal": true, "raw": true, "sourcecode": true,
+	"table": true,
+}
+
+// SpacingRST performs paranoid text spacing on a reStructuredText
+// document, such as Sphinx documentation. Only prose is changed, and the
+// adornments of section titles grow along with them. Literal blocks,
+// inline literals, roles, hyperlink references and targets, URLs,
+// backslash escapes, comments, tables, directives with their options,
+// code and math directives, and front matter are left alone, as is
+// everything else, byte for byte, as SpacingMarkdown does.
+//
+// Inline literals are seen by the rules as the text they hold, and other
+// inline markup as a word, so "見:ref:`intro`說明" becomes
+// "見 :ref:`intro` 說明", which docutils needs to recognize it.
+func (s *Spacer) SpacingRST(src []byte) []byte {
+	lines := splitLines(string(src))
+	out := append([]string(nil), lines...)
+
+	block := -1      // indentation of the explicit markup or literal block in effect, if any
+	raw := false     // whether the lines of the block are left alone
+	options := false // whether the options of a directive may follow
+	table := false
+	front := frontMatter(lines)
+	for i, line := range lines {
+		if i < front {
+			continue
+		}
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+		trimmed := strings.TrimLeft(body, " \t")
+		indent := len(body) - len(trimmed)
+
+		if trimmed == "" {
+			options, table = false, false
+			continue
+		}
+		if block >= 0 && indent <= block {
+			block = -1
+		}
+		if block >= 0 {
+			if raw || options && rstOption.MatchString(trimmed) {
+				continue
+			}
+			options = false
+		}
+
+		if table || rstTable.MatchString(trimmed) {
+			table = true
+			continue
+		}
+		if isRSTAdornment(trimmed) {
+			continue
+		}
+		if m := rstExplicit.FindStringSubmatch(trimmed); m != nil {
+			// The content of directives is prose, unless it is code or
+			// the like; comments and targets are left alone.
+			block, options = indent, true
+			raw = m[1] == "" || rstLiteralDirectives[m[1]]
+			continue
+		}
+
+		// Field names, as in docinfo, are left alone.
+		start := indent + len(rstOption.FindString(trimmed))
+		spaced := body[:start] + s.spacingSegments(splitSegments(body[start:], rstInline, decodeRST))
+		if strings.HasSuffix(strings.TrimRight(trimmed, " \t"), "::") {
+			block, raw = indent, true
+		}
+		if spaced != body {
+			out[i] = spaced + eol
+		}
+	}
+
+	spaced := s.suppressed(src, []byte(strings.Join(out, "")), rstDirective)
+	if s.rawDirectives {
+		return spaced
+	}
+
+	return []byte(growRSTAdornments(lines, splitLines(string(spaced))))
+}
+
+// growRSTAdornments makes the adornments of the section titles changed in
+// out, the spaced lines of lines, at least as long as the titles. It runs
+// after directives are honored, since Suppress only undoes changes of
+// spaces.
+func growRSTAdornments(lines, out []string) string {
+	if len(out) != len(lines) {
+		return strings.Join(out, "")
+	}
+
+	for i := range out {
+		if out[i] == lines[i] || i+1 >= len(out) || !isRSTAdornment(out[i+1]) {
+			continue
+		}
+		title := utf8.RuneCountInString(strings.TrimRight(strings.TrimPrefix(out[i], "\ufeff"), " \t\r\n"))
+		for _, j := range []int{i - 1, i + 1} {
+			if j < 0 || !isRSTAdornment(out[j]) {
+				continue
+			}
+			adornment := strings.TrimRight(out[j], " \t\r\n")
+			if grow := title - len(adornment); grow > 0 {
+				out[j] = adornment + strings.Repeat(adornment[:1], grow) + out[j][len(adornment):]
+			}
+		}
+	}
+
+	return strings.Join(out, "")
+}
+
+// isRSTAdornment reports whether line is the overline or underline of a
+// section title, or a transition: a repeated punctuation character.
+func isRSTAdornment(line string) bool {
+	line = strings.TrimRight(line, " \t\r\n")
+	if len(line) < 4 {
+		return false
+	}
+	c := line[0]
+	if c > '~' || c == ' ' || isAlnum(c) || c <= ' ' {
+		return false
+	}
+
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// decodeRST tells how the rules see a piece of inline markup: inline
+// literals as the text they hold, backslash escapes as the character they
+// escape, anything else as a word.
+func decodeRST(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, "``"):
+		return raw[2 : len(raw)-2]
+	case raw[0] == '\\':
+		return raw[1:]
+	}
+
+	return "0"
+}
@@ -0,0 +1,140 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type RSTTestSuite struct {
+	suite.Suite
+}
+
+func TestRSTTestSuite(t *testing.T) {
+	suite.Run(t, new(RSTTestSuite))
+}
+
+func (suite *RSTTestSuite) TestSpacingRST() {
+	src := "=========\n" +
+		"第1章Chapter\n" +
+		"=========\n" +
+		"\n" +
+		".. _第1章target:\n" +
+		"\n" +
+		"當你凝視著bug，bug也凝視著你\n" +
+		"使用``go test``指令，見:ref:`intro`說明與PEP-8_規範\n" +
+		"\n" +
+		"範例Example::\n" +
+		"\n" +
+		"    fmt.Println(\"中文English\")\n" +
+		"\n" +
+		".. code-block:: go\n" +
+		"   :caption: 範例Example\n" +
+		"\n" +
+		"   x := \"中文English\"\n" +
+		"\n" +
+		".. note::\n" +
+		"   :class: 中文abc\n" +
+		"\n" +
+		"   注意Note內容\n" +
+		"\n" +
+		".. 註解comment\n" +
+		"   還是註解comment\n" +
+		"\n" +
+		"+------+----+\n" +
+		"| 中文 | A  |\n" +
+		"+------+----+\n" +
+		"| 表格Table |\n" +
+		"\n" +
+		":作者Author: 與PM戰鬥的人\n"
+	suite.Equal("=============\n"+
+		"第 1 章 Chapter\n"+
+		"=============\n"+
+		"\n"+
+		".. _第1章target:\n"+
+		"\n"+
+		"當你凝視著 bug，bug 也凝視著你\n"+
+		"使用 ``go test`` 指令，見 :ref:`intro` 說明與 PEP-8_ 規範\n"+
+		"\n"+
+		"範例 Example::\n"+
+		"\n"+
+		"    fmt.Println(\"中文English\")\n"+
+		"\n"+
+		".. code-block:: go\n"+
+		"   :caption: 範例Example\n"+
+		"\n"+
+		"   x := \"中文English\"\n"+
+		"\n"+
+		".. note::\n"+
+		"   :class: 中文abc\n"+
+		"\n"+
+		"   注意 Note 內容\n"+
+		"\n"+
+		".. 註解comment\n"+
+		"   還是註解comment\n"+
+		"\n"+
+		"+------+----+\n"+
+		"| 中文 | A  |\n"+
+		"+------+----+\n"+
+		"| 表格Table |\n"+
+		"\n"+
+		":作者Author: 與 PM 戰鬥的人\n", string(pangu.NewSpacer().SpacingRST([]byte(src))))
+}
+
+func (suite *RSTTestSuite) TestDirectives() {
+	src := "第1章Chapter\n" +
+		"==========\n" +
+		"\n" +
+		".. pangu-disable\n" +
+		"\n" +
+		"保留Keep原樣\n" +
+		"\n" +
+		".. pangu-enable\n" +
+		"\n" +
+		"之後After\n"
+	s := pangu.NewSpacer()
+	suite.Equal("第 1 章 Chapter\n"+
+		"=============\n"+
+		"\n"+
+		".. pangu-disable\n"+
+		"\n"+
+		"保留Keep原樣\n"+
+		"\n"+
+		".. pangu-enable\n"+
+		"\n"+
+		"之後 After\n", string(s.SpacingRST([]byte(src))))
+
+	problems, err := s.CheckMode([]byte(src), func(s *pangu.Spacer, src []byte) ([]byte, error) {
+		return s.SpacingRST(src), nil
+	})
+	suite.Nil(err)
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+		suite.Equal(" ", p.Fix.New)
+	}
+	suite.Equal([]string{"1:2: missing space", "1:3: missing space", "1:4: missing space", "10:3: missing space"}, got)
+
+	// Adornments already long enough are left alone.
+	src = "中文abc\n==========\n"
+	suite.Equal("中文 abc\n==========\n", string(s.SpacingRST([]byte(src))))
+}
+
+func (suite *RSTTestSuite) TestProtections() {
+	src := "---\n" +
+		"title: 文件Title\n" +
+		"---\n" +
+		"\n" +
+		"使用\\ ``go test``\\ 指令與`連結Link <https://example.com/中文abc>`__說明\n" +
+		"\n" +
+		"__ https://example.com/中文abc\n" +
+		"__ 中文abc_\n"
+	suite.Equal("---\n"+
+		"title: 文件Title\n"+
+		"---\n"+
+		"\n"+
+		"使用\\ ``go test``\\ 指令與 `連結Link <https://example.com/中文abc>`__ 說明\n"+
+		"\n"+
+		"__ https://example.com/中文abc\n"+
+		"__ 中文abc_\n", string(pangu.NewSpacer().SpacingRST([]byte(src))))
+}
@@ -0,0 +1,137 @@
+package pangu
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A segment is a piece of a document as seen by the spacing rules.
+//
+// Literal segments are plain text that may be edited. The other ones are
+// opaque, such as escape sequences or markup: the rules see text instead
+// of raw, and spaces are only ever inserted at their edges.
+type segment struct {
+	raw     string // bytes in the document
+	text    string // what the spacing rules see
+	literal bool
+	open    bool // for opaque segments without text: spaces go before it, not after
+}
+
+func literal(text string) segment {
+	return segment{raw: text, text: text, literal: true}
+}
+
+func opaque(raw, text string) segment {
+	return segment{raw: raw, text: text}
+}
+
+// spacingSegments performs spacing on the text made of segs, so that the
+// rules see the context across segment boundaries, and returns the raw
+// document with the resulting edits applied to literal segments only.
+func (s *Spacer) spacingSegments(segs []segment) string {
+	var b strings.Builder
+	starts := make([]int, len(segs)+1)
+	for i, seg := range segs {
+		starts[i] = b.Len()
+		b.WriteString(seg.text)
+	}
+	starts[len(segs)] = b.Len()
+	text := b.String()
+
+	gaps := make([]string, len(segs)+1) // insertions before each segment
+	inner := make([][]edit, len(segs))  // edits inside literal segments
+
+	for _, e := range diff(text, s.SpacingText(text)) {
+		if e.ins != "" {
+			p := e.start
+			i := sort.SearchInts(starts, p)
+			if starts[i] == p {
+				// Spaces go after closing markup and before opening markup.
+				for i < len(segs) && segs[i].text == "" && !segs[i].literal && !segs[i].open {
+					i++
+				}
+				gaps[i] += e.ins
+			} else if segs[i-1].literal {
+				inner[i-1] = append(inner[i-1], edit{p - starts[i-1], p - starts[i-1], e.ins})
+			}
+		}
+
+		for q := e.start; q < e.end; q++ {
+			i := sort.SearchInts(starts, q+1) - 1
+			if segs[i].literal {
+				inner[i] = append(inner[i], edit{q - starts[i], q - starts[i] + 1, ""})
+			}
+		}
+	}
+
+	var raw strings.Builder
+	for i, seg := range segs {
+		raw.WriteString(gaps[i])
+		if seg.literal {
+			raw.WriteString(apply(seg.text, inner[i]))
+		} else {
+			raw.WriteString(seg.raw)
+		}
+	}
+	raw.WriteString(gaps[len(segs)])
+
+	return raw.String()
+}
+
+// escapedSegments splits the body of a double-quoted string literal
+// with C-like escape sequences into segments. Escape sequences are
+// opaque and seen by the rules as the character they stand for. Matches
+// of placeholder, if not nil, are opaque and seen as they are.
+func escapedSegments(body string, placeholder *regexp.Regexp) []segment {
+	var segs []segment
+	text := func(t string) {
+		if t == "" {
+			return
+		}
+		if placeholder == nil {
+			segs = append(segs, literal(t))
+			return
+		}
+		last := 0
+		for _, loc := range placeholder.FindAllStringIndex(t, -1) {
+			if loc[0] > last {
+				segs = append(segs, literal(t[last:loc[0]]))
+			}
+			segs = append(segs, opaque(t[loc[0]:loc[1]], t[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		if last < len(t) {
+			segs = append(segs, literal(t[last:]))
+		}
+	}
+
+	for len(body) > 0 {
+		i := strings.IndexByte(body, '\\')
+		if i < 0 {
+			text(body)
+			break
+		}
+		text(body[:i])
+		body = body[i:]
+
+		if strings.HasPrefix(body, `\/`) {
+			// Only valid in JSON, where it stands for a slash.
+			segs = append(segs, opaque(body[:2], "/"))
+			body = body[2:]
+			continue
+		}
+
+		value, _, tail, err := strconv.UnquoteChar(body, '"')
+		if err != nil {
+			// Leave malformed escape sequences and the rest alone.
+			segs = append(segs, opaque(body, body))
+			break
+		}
+		segs = append(segs, opaque(body[:len(body)-len(tail)], string(value)))
+		body = tail
+	}
+
+	return segs
+}
@@ -0,0 +1,96 @@
+//go:build go1.21
+// +build go1.21
+
+package pangu
+
+import (
+	gocontext "context"
+	"log/slog"
+)
+
+// SlogHandler returns a handler that performs paranoid text spacing on
+// log records before passing them to next, with the default rules. See
+// Spacer.SlogHandler.
+func SlogHandler(next slog.Handler, keys ...string) slog.Handler {
+	return defaultSpacer.SlogHandler(next, keys...)
+}
+
+// SlogHandler returns a handler that performs paranoid text spacing on
+// the messages of log records, and on the string values of the attributes
+// with the given keys, at any depth of groups, before passing the records
+// to next. Keys themselves and the other values are left alone:
+//
+//	logger := slog.New(pangu.SlogHandler(slog.NewJSONHandler(os.Stderr, nil), "reason"))
+//	logger.Info("上傳檔案失敗", "reason", "磁碟空間不足disk full")
+func (s *Spacer) SlogHandler(next slog.Handler, keys ...string) slog.Handler {
+	h := &slogHandler{next: next, spacer: s, keys: map[string]bool{}}
+	for _, key := range keys {
+		h.keys[key] = true
+	}
+
+	return h
+}
+
+type slogHandler struct {
+	next   slog.Handler
+	spacer *Spacer
+	keys   map[string]bool
+}
+
+func (h *slogHandler) Enabled(ctx gocontext.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx gocontext.Context, r slog.Record) error {
+	if len(h.keys) == 0 {
+		r.Message = h.spacer.SpacingText(r.Message)
+		return h.next.Handle(ctx, r)
+	}
+
+	// Attributes cannot be replaced in place, so a new record is made.
+	out := slog.NewRecord(r.Time, r.Level, h.spacer.SpacingText(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, out)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	spaced := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		spaced[i] = h.attr(a)
+	}
+
+	return &slogHandler{next: h.next.WithAttrs(spaced), spacer: h.spacer, keys: h.keys}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{next: h.next.WithGroup(name), spacer: h.spacer, keys: h.keys}
+}
+
+// attr returns a with spacing performed on its value if its key is one
+// of the keys, or on the attributes it groups.
+func (h *slogHandler) attr(a slog.Attr) slog.Attr {
+	if len(h.keys) == 0 {
+		return a
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if h.keys[a.Key] {
+			return slog.String(a.Key, h.spacer.SpacingText(v.String()))
+		}
+	case slog.KindGroup:
+		group := v.Group()
+		spaced := make([]any, len(group))
+		for i, g := range group {
+			spaced[i] = h.attr(g)
+		}
+		return slog.Group(a.Key, spaced...)
+	}
+
+	return a
+}
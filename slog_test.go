@@ -0,0 +1,51 @@
+//go:build go1.21
+// +build go1.21
+
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"log/slog"
+	"testing"
+)
+
+type SlogTestSuite struct {
+	suite.Suite
+	buf bytes.Buffer
+}
+
+func TestSlogTestSuite(t *testing.T) {
+	suite.Run(t, new(SlogTestSuite))
+}
+
+func (suite *SlogTestSuite) logger(keys ...string) *slog.Logger {
+	suite.buf.Reset()
+	next := slog.NewTextHandler(&suite.buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	return slog.New(pangu.SlogHandler(next, keys...))
+}
+
+func (suite *SlogTestSuite) TestMessage() {
+	suite.logger().Info("上傳檔案失敗upload failed", "reason", "磁碟空間不足disk full")
+	suite.Equal("level=INFO msg=\"上傳檔案失敗 upload failed\" reason=\"磁碟空間不足disk full\"\n", suite.buf.String())
+}
+
+func (suite *SlogTestSuite) TestKeys() {
+	logger := suite.logger("reason", "名稱Name").With("reason", "預設原因default")
+	logger.WithGroup("req").Warn("上傳檔案失敗",
+		"reason", "磁碟空間不足disk full",
+		"名稱Name", "檔案File",
+		"path", "/中文abc",
+		slog.Group("detail", "reason", "配額quota", "count", 3),
+	)
+	suite.Equal("level=WARN msg=上傳檔案失敗 reason=\"預設原因 default\" req.reason=\"磁碟空間不足 disk full\" req.名稱Name=\"檔案 File\" req.path=/中文abc req.detail.reason=\"配額 quota\" req.detail.count=3\n", suite.buf.String())
+}
@@ -0,0 +1,307 @@
+package pangu
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var defaultSpacer = NewSpacer()
+
+// A Spacer performs paranoid text spacing with its own set of enabled
+// rules, protected patterns and protected terms. The zero value is ready
+// to use and behaves exactly like the package-level SpacingText.
+//
+// A Spacer is safe for concurrent use once configured, and must not be
+// copied after first use.
+type Spacer struct {
+	disabled map[string]bool
+	patterns []*regexp.Regexp
+
+	rawDirectives bool           // whether directives are left to the caller, see CheckMode
+	directives    *regexp.Regexp // pattern of the directives of the last mode run, if rawDirectives
+
+	mu    sync.Mutex
+	terms []string
+	dict  *automaton
+}
+
+// NewSpacer returns a Spacer with every rule enabled and nothing protected.
+func NewSpacer() *Spacer {
+	return &Spacer{}
+}
+
+// Disable turns off the named rules. See Rules for the valid names.
+func (s *Spacer) Disable(names ...string) error {
+	for _, name := range names {
+		if !isRule(name) {
+			return fmt.Errorf("pangu: unknown rule %q", name)
+		}
+		if s.disabled == nil {
+			s.disabled = make(map[string]bool)
+		}
+		s.disabled[name] = true
+	}
+
+	return nil
+}
+
+// Enable turns the named rules back on. See Rules for the valid names.
+func (s *Spacer) Enable(names ...string) error {
+	for _, name := range names {
+		if !isRule(name) {
+			return fmt.Errorf("pangu: unknown rule %q", name)
+		}
+		delete(s.disabled, name)
+	}
+
+	return nil
+}
+
+// A profile is a named set of rules following the spacing convention of
+// a locale.
+type profile struct {
+	name     string
+	disabled []string
+}
+
+var profiles = []profile{
+	{"zh", nil},
+	{"ja", []string{"quote", "hash", "operator", "bracket", "symbol"}},
+}
+
+// Profiles returns the names of all locale profiles. The names are
+// accepted by Spacer.UseProfile.
+func Profiles() []string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.name
+	}
+
+	return names
+}
+
+// UseProfile turns on exactly the rules of the named locale profile,
+// turning the others off:
+//
+//	zh  every rule, the convention of Traditional and Simplified Chinese
+//	ja  only ans, the convention of Japanese
+//
+// Japanese style guides, such as the one of Microsoft, put a space between
+// Japanese and half-width letters and numbers, but none around symbols,
+// operators, brackets or quotes.
+//
+// UseProfile replaces the rules set before, including the ones turned off
+// with Disable, so call it first and adjust its rules with Enable and
+// Disable afterwards.
+func (s *Spacer) UseProfile(name string) error {
+	for _, p := range profiles {
+		if p.name == name {
+			s.disabled = nil
+			return s.Disable(p.disabled...)
+		}
+	}
+
+	return fmt.Errorf("pangu: unknown profile %q", name)
+}
+
+func isRule(name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProtectPattern marks every match of the given patterns as protected.
+// Spacing is never inserted or removed inside a protected match, though
+// it may still be inserted right before or after it.
+func (s *Spacer) ProtectPattern(patterns ...*regexp.Regexp) {
+	s.patterns = append(s.patterns, patterns...)
+}
+
+// SpacingText performs paranoid text spacing on text using the rules
+// and protections of s.
+func (s *Spacer) SpacingText(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+
+	spaced := s.spacing(text)
+	if spaced == text {
+		return text
+	}
+
+	protected := s.protected(text)
+	if len(protected) == 0 {
+		return spaced
+	}
+
+	var kept []edit
+	for _, e := range diff(text, spaced) {
+		if !insideAny(e, protected) {
+			kept = append(kept, e)
+		}
+	}
+
+	return apply(text, kept)
+}
+
+// SpacingFile reads the file named by filename, performs paranoid text
+// spacing on its contents using the rules and protections of s and
+// writes the processed content to w. Lines suppressed by directives
+// (see DirectiveDisable) are written unchanged.
+// A successful call returns err == nil.
+func (s *Spacer) SpacingFile(filename string, w io.Writer) (err error) {
+	fr, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	b, err := ioutil.ReadAll(fr)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(s.SpacingDocument(b))
+
+	return err
+}
+
+// SpacingDocument performs paranoid text spacing on a plain text
+// document, line by line, the way SpacingFile does. Lines suppressed by
+// directives are left unchanged.
+func (s *Spacer) SpacingDocument(src []byte) []byte {
+	return s.suppressed(src, []byte(s.spacingLines(string(src))), anyDirective)
+}
+
+// spacingLines performs spacing on text line by line, ignoring
+// directives.
+func (s *Spacer) spacingLines(text string) string {
+	var b strings.Builder
+	for _, line := range splitLines(text) {
+		b.WriteString(s.SpacingText(line))
+	}
+
+	return b.String()
+}
+
+// spacing runs every enabled rule on text, ignoring protections.
+func (s *Spacer) spacing(text string) string {
+	for _, r := range rules {
+		if !s.disabled[r.name] {
+			text = r.fn(text)
+		}
+	}
+
+	return text
+}
+
+// span is a half-open byte range [start, end) of a text.
+type span struct {
+	start, end int
+}
+
+// protected returns the sorted byte ranges of text that s must not change.
+func (s *Spacer) protected(text string) []span {
+	var spans []span
+	for _, re := range s.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				spans = append(spans, span{loc[0], loc[1]})
+			}
+		}
+	}
+	if dict := s.dictionary(); dict != nil {
+		spans = append(spans, dict.find(text)...)
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	return spans
+}
+
+// An edit replaces the bytes text[start:end] with ins.
+type edit struct {
+	start, end int
+	ins        string
+}
+
+// insideAny reports whether e touches the interior of any of the spans.
+// Insertions exactly at the edge of a span are not inside it.
+func insideAny(e edit, spans []span) bool {
+	for _, sp := range spans {
+		if e.start == e.end {
+			if e.start > sp.start && e.start < sp.end {
+				return true
+			}
+		} else if e.start < sp.end && e.end > sp.start {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r' || b == '\v'
+}
+
+// diff returns the edits that turn from into to. It relies on the fact
+// that the spacing rules only ever insert or remove ASCII whitespace.
+func diff(from, to string) []edit {
+	var edits []edit
+	push := func(start, end int, ins string) {
+		if n := len(edits); n > 0 && edits[n-1].end == start {
+			edits[n-1].end = end
+			edits[n-1].ins += ins
+			return
+		}
+		edits = append(edits, edit{start, end, ins})
+	}
+
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			i++
+			j++
+		case j < len(to) && isSpace(to[j]):
+			push(i, i, to[j:j+1])
+			j++
+		case i < len(from) && isSpace(from[i]):
+			push(i, i+1, "")
+			i++
+		default:
+			// Not a whitespace-only change; replace the rest wholesale.
+			push(i, len(from), to[j:])
+			i, j = len(from), len(to)
+		}
+	}
+
+	return edits
+}
+
+// apply returns text with the edits applied. The edits must be sorted
+// and must not overlap.
+func apply(text string, edits []edit) string {
+	var buf []byte
+	last := 0
+	for _, e := range edits {
+		buf = append(buf, text[last:e.start]...)
+		buf = append(buf, e.ins...)
+		last = e.end
+	}
+	buf = append(buf, text[last:]...)
+
+	return string(buf)
+}
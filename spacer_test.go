@@ -0,0 +1,84 @@
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"regexp"
+	"testing"
+)
+
+type SpacerTestSuite struct {
+	suite.Suite
+}
+
+func TestSpacerTestSuite(t *testing.T) {
+	suite.Run(t, new(SpacerTestSuite))
+}
+
+func (suite *SpacerTestSuite) TestZeroValue() {
+	var s pangu.Spacer
+	suite.Equal(`新八的構造成分有 95% 是眼鏡、3% 是水、2% 是垃圾`, s.SpacingText(`新八的構造成分有95%是眼鏡、3%是水、2%是垃圾`))
+}
+
+func (suite *SpacerTestSuite) TestDisable() {
+	s := pangu.NewSpacer()
+	suite.NoError(s.Disable("hash"))
+	suite.Equal(`前面#H2G2 後面`, s.SpacingText(`前面#H2G2後面`))
+
+	suite.NoError(s.Enable("hash"))
+	suite.Equal(`前面 #H2G2 後面`, s.SpacingText(`前面#H2G2後面`))
+}
+
+func (suite *SpacerTestSuite) TestUnknownRule() {
+	s := pangu.NewSpacer()
+	suite.Error(s.Disable("nope"))
+	suite.Error(s.Enable("nope"))
+}
+
+func (suite *SpacerTestSuite) TestRules() {
+	suite.Equal([]string{"quote", "hash", "operator", "bracket", "symbol", "ans"}, pangu.Rules())
+}
+
+func (suite *SpacerTestSuite) TestUseProfile() {
+	zh, ja := pangu.NewSpacer(), pangu.NewSpacer()
+	suite.NoError(zh.UseProfile("zh"))
+	suite.NoError(ja.UseProfile("ja"))
+	suite.Equal(`Go 言語 (v1.22) を使う`, zh.SpacingText(`Go言語(v1.22)を使う`))
+	suite.Equal(`Go 言語(v1.22)を使う`, ja.SpacingText(`Go言語(v1.22)を使う`))
+	suite.Equal(`テスト #H2G2 です`, zh.SpacingText(`テスト#H2G2です`))
+	suite.Equal(`テスト#H2G2 です`, ja.SpacingText(`テスト#H2G2です`))
+
+	suite.NoError(ja.Enable("hash"))
+	suite.Equal(`テスト #H2G2 です`, ja.SpacingText(`テスト#H2G2です`))
+
+	// The profile replaces the rules turned off before.
+	suite.NoError(zh.Disable("hash"))
+	suite.NoError(zh.UseProfile("zh"))
+	suite.Equal(`前面 #H2G2 後面`, zh.SpacingText(`前面#H2G2後面`))
+
+	suite.Error(zh.UseProfile("nope"))
+	suite.Equal([]string{"zh", "ja"}, pangu.Profiles())
+}
+
+func (suite *SpacerTestSuite) TestProtectPattern() {
+	s := pangu.NewSpacer()
+	s.ProtectPattern(regexp.MustCompile(`https?://\S+`))
+	suite.Equal(`請看 https://example.com/中文abc 網站`, s.SpacingText(`請看https://example.com/中文abc 網站`))
+	suite.Equal(`與 PM 戰鬥的人`, s.SpacingText(`與PM戰鬥的人`))
+}
+
+func (suite *SpacerTestSuite) TestSpacingFile() {
+	s := pangu.NewSpacer()
+	var buf bytes.Buffer
+	suite.NoError(s.SpacingFile("_fixtures/test_file.txt", &buf))
+	expected, err := ioutil.ReadFile("_fixtures/test_file.expected.txt")
+	checkError(err)
+	suite.Equal(string(expected), buf.String())
+}
+
+func (suite *SpacerTestSuite) TestSpacingDocument() {
+	src := "與PM戰鬥的人\n// pangu-ignore-next-line\n不要改PM\n"
+	suite.Equal("與 PM 戰鬥的人\n// pangu-ignore-next-line\n不要改PM\n", string(pangu.NewSpacer().SpacingDocument([]byte(src))))
+}
@@ -0,0 +1,141 @@
+package pangu
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SpaceStruct performs paranoid text spacing on the strings held by v,
+// with the default rules. See Spacer.SpaceStruct.
+func SpaceStruct(v interface{}) error {
+	return defaultSpacer.SpaceStruct(v)
+}
+
+// SpaceStruct performs paranoid text spacing in place on the strings
+// held by v, which must be a pointer, a map or a slice, such as a pointer
+// to a response about to be serialized. Structs, slices, arrays, maps and
+// pointers are walked, and values reached more than once, as in cycles,
+// are only walked the first time.
+//
+// Every string of a struct is spaced, except in fields tagged
+// `pangu:"-"`. If some fields of a struct are tagged `pangu:"space"`,
+// only those are spaced, while structs in the other fields still decide
+// for themselves. Map keys and unexported fields are left alone, except
+// embedded structs, whose exported fields are spaced.
+func (s *Spacer) SpaceStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+	default:
+		return fmt.Errorf("pangu: SpaceStruct of non-pointer %T", v)
+	}
+
+	w := &structWalker{spacer: s, seen: map[structVisit]bool{}}
+
+	return w.walk(rv, true)
+}
+
+// structVisit identifies a value reached through a pointer, a map or a
+// slice, along with how it is walked. Slices sharing an array are told
+// apart by their length, so that one does not hide the other.
+type structVisit struct {
+	ptr  uintptr
+	typ  reflect.Type
+	len  int
+	strs bool
+}
+
+type structWalker struct {
+	spacer *Spacer
+	seen   map[structVisit]bool
+}
+
+// walk performs spacing on the strings held by v, or only on the ones in
+// nested structs unless strs.
+func (w *structWalker) walk(v reflect.Value, strs bool) error {
+	switch v.Kind() {
+	case reflect.String:
+		if strs && v.CanSet() {
+			v.SetString(w.spacer.SpacingText(v.String()))
+		}
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		visit := structVisit{v.Pointer(), v.Type(), 0, strs}
+		if v.Kind() == reflect.Slice {
+			visit.len = v.Len()
+		}
+		if w.seen[visit] {
+			return nil
+		}
+		w.seen[visit] = true
+
+		switch v.Kind() {
+		case reflect.Ptr:
+			return w.walk(v.Elem(), strs)
+		case reflect.Map:
+			// Map values cannot be set in place, so copies are walked.
+			iter := v.MapRange()
+			for iter.Next() {
+				e := reflect.New(v.Type().Elem()).Elem()
+				e.Set(iter.Value())
+				if err := w.walk(e, strs); err != nil {
+					return err
+				}
+				v.SetMapIndex(iter.Key(), e)
+			}
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), strs); err != nil {
+				return err
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		if err := w.walk(e, strs); err != nil {
+			return err
+		}
+		v.Set(e)
+	case reflect.Struct:
+		return w.walkStruct(v)
+	}
+
+	return nil
+}
+
+func (w *structWalker) walkStruct(v reflect.Value) error {
+	t := v.Type()
+	optIn := false
+	for i := 0; i < t.NumField(); i++ {
+		switch tag := t.Field(i).Tag.Get("pangu"); tag {
+		case "", "-":
+		case "space":
+			optIn = true
+		default:
+			return fmt.Errorf("pangu: unknown tag %q on %s.%s", tag, t, t.Field(i).Name)
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("pangu")
+		// Embedded structs are walked even if unexported, for the fields
+		// they promote.
+		if !f.IsExported() && !f.Anonymous || tag == "-" {
+			continue
+		}
+		if err := w.walk(v.Field(i), !optIn || tag == "space"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type StructTestSuite struct {
+	suite.Suite
+}
+
+func TestStructTestSuite(t *testing.T) {
+	suite.Run(t, new(StructTestSuite))
+}
+
+type author struct {
+	Name string
+	URL  string `pangu:"-"`
+}
+
+type comment struct {
+	ID      string
+	Body    string   `pangu:"space"`
+	Tags    []string `pangu:"space"`
+	Author  *author
+	Replies []*comment
+}
+
+type article struct {
+	Title    string
+	Author   author
+	Labels   map[string]string
+	Extra    map[string]interface{}
+	Comments []comment
+	Related  *article
+	Counts   [2]string
+	title    string
+}
+
+func (suite *StructTestSuite) TestSpaceStruct() {
+	a := &article{
+		Title:  "與PM戰鬥的人",
+		Author: author{Name: "作者Vinta", URL: "https://example.com/中文abc"},
+		Labels: map[string]string{"中文abc": "標籤Label"},
+		Extra:  map[string]interface{}{"note": "附註Note", "count": 3},
+		Comments: []comment{{
+			ID:     "中文abc",
+			Body:   "當你凝視著bug",
+			Tags:   []string{"標籤Tag"},
+			Author: &author{Name: "讀者Reader"},
+		}},
+		Counts: [2]string{"第1名", "第2名"},
+		title:  "中文abc",
+	}
+	a.Related = a
+	a.Comments[0].Replies = []*comment{&a.Comments[0]}
+
+	suite.Nil(pangu.SpaceStruct(a))
+	suite.Equal("與 PM 戰鬥的人", a.Title)
+	suite.Equal(author{Name: "作者 Vinta", URL: "https://example.com/中文abc"}, a.Author)
+	suite.Equal(map[string]string{"中文abc": "標籤 Label"}, a.Labels)
+	suite.Equal(map[string]interface{}{"note": "附註 Note", "count": 3}, a.Extra)
+	suite.Equal("中文abc", a.Comments[0].ID)
+	suite.Equal("當你凝視著 bug", a.Comments[0].Body)
+	suite.Equal([]string{"標籤 Tag"}, a.Comments[0].Tags)
+	suite.Equal("讀者 Reader", a.Comments[0].Author.Name)
+	suite.Equal([2]string{"第 1 名", "第 2 名"}, a.Counts)
+	suite.Equal("中文abc", a.title)
+}
+
+type base struct {
+	Summary string
+	note    string
+}
+
+type post struct {
+	base
+	Title string
+}
+
+func (suite *StructTestSuite) TestEmbedded() {
+	p := &post{base: base{Summary: "摘要Summary", note: "中文abc"}, Title: "與PM戰鬥的人"}
+	suite.Nil(pangu.SpaceStruct(p))
+	suite.Equal(post{base: base{Summary: "摘要 Summary", note: "中文abc"}, Title: "與 PM 戰鬥的人"}, *p)
+}
+
+func (suite *StructTestSuite) TestShared() {
+	all := []string{"中文abc", "與PM戰鬥的人"}
+	v := &struct {
+		First []string
+		All   []string
+	}{all[:1], all}
+	suite.Nil(pangu.SpaceStruct(v))
+	suite.Equal([]string{"中文 abc", "與 PM 戰鬥的人"}, all)
+
+	title := "與PM戰鬥的人"
+	w := &struct {
+		Raw    *string
+		Spaced *string `pangu:"space"`
+	}{&title, &title}
+	suite.Nil(pangu.SpaceStruct(w))
+	suite.Equal("與 PM 戰鬥的人", title)
+}
+
+func (suite *StructTestSuite) TestErrors() {
+	suite.Error(pangu.SpaceStruct(article{}))
+
+	v := &struct {
+		Title string `pangu:"spaced"`
+	}{}
+	suite.EqualError(pangu.SpaceStruct(v), `pangu: unknown tag "spaced" on struct { Title string "pangu:\"spaced\"" }.Title`)
+
+	s := []string{"中文abc"}
+	suite.Nil(pangu.SpaceStruct(s))
+	suite.Equal([]string{"中文 abc"}, s)
+}
@@ -0,0 +1,105 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// subtitleTag matches styling markup in cue text: HTML-like tags such as
+// <i> or <c.yellow>, WebVTT timestamps such as <00:01.000>, and SRT
+// override blocks such as {\an8}.
+var subtitleTag = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9_.-]*(?:\s[^<>]*)?>|<(?:\d+:)?\d{2}:\d{2}\.\d{3}>|\{\\[^{}]*\}`)
+
+// SpacingSRT performs paranoid text spacing on a SubRip (.srt) subtitle
+// file. Only the cue text is changed; cue numbers, timings and styling
+// tags are left alone, as is everything else, byte for byte.
+func (s *Spacer) SpacingSRT(src []byte) []byte {
+	return s.suppressed(src, s.spacingSubtitle(src, false), nil)
+}
+
+// SpacingWebVTT performs paranoid text spacing on a WebVTT (.vtt) file.
+// Only the cue text is changed; the header, cue identifiers, timings,
+// cue settings, styling tags, and NOTE, STYLE and REGION blocks are left
+// alone, as is everything else, byte for byte.
+func (s *Spacer) SpacingWebVTT(src []byte) []byte {
+	return s.suppressed(src, s.spacingSubtitle(src, true), vttDirective)
+}
+
+func (s *Spacer) spacingSubtitle(src []byte, vtt bool) []byte {
+	var out strings.Builder
+	lines := splitLines(string(src))
+	for len(lines) > 0 {
+		// A block runs until the next blank line.
+		n := 0
+		for n < len(lines) && strings.TrimSpace(lines[n]) != "" {
+			n++
+		}
+		block := lines[:n]
+
+		// The cue text follows the timing line; anything without one,
+		// such as the WebVTT header or a NOTE, is not a cue.
+		timing := -1
+		for i, line := range block {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if vtt && len(block) > 0 && isVTTMetadata(block[0]) {
+			timing = -1
+		}
+
+		for i, line := range block {
+			if timing < 0 || i <= timing {
+				out.WriteString(line)
+				continue
+			}
+			out.WriteString(s.spacingCueLine(line))
+		}
+
+		for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+			out.WriteString(lines[n])
+			n++
+		}
+		lines = lines[n:]
+	}
+
+	return []byte(out.String())
+}
+
+func isVTTMetadata(line string) bool {
+	for _, prefix := range []string{"WEBVTT", "NOTE", "STYLE", "REGION"} {
+		if strings.HasPrefix(line, prefix) {
+			rest := strings.TrimPrefix(line, prefix)
+			if rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n' {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// spacingCueLine performs spacing on a line of cue text, treating the
+// styling tags as opaque.
+func (s *Spacer) spacingCueLine(line string) string {
+	body := strings.TrimRight(line, "\r\n")
+	eol := line[len(body):]
+
+	var segs []segment
+	last := 0
+	for _, loc := range subtitleTag.FindAllStringIndex(body, -1) {
+		if loc[0] > last {
+			segs = append(segs, literal(body[last:loc[0]]))
+		}
+		tag := segment{raw: body[loc[0]:loc[1]]}
+		tag.open = !strings.HasPrefix(tag.raw, "</")
+		segs = append(segs, tag)
+		last = loc[1]
+	}
+	if last < len(body) {
+		segs = append(segs, literal(body[last:]))
+	}
+
+	return s.spacingSegments(segs) + eol
+}
@@ -0,0 +1,66 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type SubtitleTestSuite struct {
+	suite.Suite
+}
+
+func TestSubtitleTestSuite(t *testing.T) {
+	suite.Run(t, new(SubtitleTestSuite))
+}
+
+func (suite *SubtitleTestSuite) TestSpacingSRT() {
+	src := "1\r\n" +
+		"00:01:02,500 --> 00:01:04,000\r\n" +
+		"{\\an8}與PM戰鬥的人\r\n" +
+		"<i>應當小心</i>自己不要成為PM\r\n" +
+		"\r\n" +
+		"2\r\n" +
+		"00:01:05,000 --> 00:01:06,000\r\n" +
+		"當你凝視著<b>bug</b>，bug也凝視著你"
+
+	suite.Equal("1\r\n"+
+		"00:01:02,500 --> 00:01:04,000\r\n"+
+		"{\\an8}與 PM 戰鬥的人\r\n"+
+		"<i>應當小心</i>自己不要成為 PM\r\n"+
+		"\r\n"+
+		"2\r\n"+
+		"00:01:05,000 --> 00:01:06,000\r\n"+
+		"當你凝視著 <b>bug</b>，bug 也凝視著你", string(pangu.NewSpacer().SpacingSRT([]byte(src))))
+}
+
+func (suite *SubtitleTestSuite) TestSpacingWebVTT() {
+	src := "WEBVTT 中文Title\n" +
+		"\n" +
+		"NOTE 這是PM寫的\n" +
+		"\n" +
+		"STYLE\n" +
+		"::cue(.中文) { color: red }\n" +
+		"\n" +
+		"cue1中文\n" +
+		"00:01.000 --> 00:04.000 align:start line:0%\n" +
+		"<v 老闆Boss>與PM戰鬥的人\n" +
+		"<c.yellow>應當</c>小心<00:02.000>自己不要成為PM\n"
+
+	suite.Equal("WEBVTT 中文Title\n"+
+		"\n"+
+		"NOTE 這是PM寫的\n"+
+		"\n"+
+		"STYLE\n"+
+		"::cue(.中文) { color: red }\n"+
+		"\n"+
+		"cue1中文\n"+
+		"00:01.000 --> 00:04.000 align:start line:0%\n"+
+		"<v 老闆Boss>與 PM 戰鬥的人\n"+
+		"<c.yellow>應當</c>小心<00:02.000>自己不要成為 PM\n", string(pangu.NewSpacer().SpacingWebVTT([]byte(src))))
+}
+
+func (suite *SubtitleTestSuite) TestRoundTrip() {
+	src := "1\n00:00:01,000 --> 00:00:02,000\n與 PM 戰鬥的人\n\n\n"
+	suite.Equal(src, string(pangu.NewSpacer().SpacingSRT([]byte(src))))
+}
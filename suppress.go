@@ -0,0 +1,185 @@
+package pangu
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Directives recognized by SpacingFile, SpacingDocument and the
+// format-aware modes for formats with comments:
+//
+//	pangu-disable            leaves the following lines alone
+//	pangu-enable             performs spacing again after pangu-disable
+//	pangu-ignore-next-line   leaves only the next line alone
+//	pangu-disable-file       leaves the whole file alone
+//
+// A directive must start a comment, as in "# pangu-disable" or
+// "<!-- pangu-disable -->", and be followed by whitespace or the end of
+// the comment, so that "pangu-disable-next-line" or a mention in prose is
+// not taken for one. Comments are written in the syntax of the format,
+// such as "#" in YAML, TOML and PO files, "//" and "/*" in Go source and
+// .strings files, "<!--" in Markdown and XML, "%" in LaTeX, ".." in
+// reStructuredText, "//" in AsciiDoc and "NOTE" in WebVTT. Plain text
+// takes any of them, as well as ";" and "--". CSV, JSON and SubRip files
+// have no comments, and directives are not recognized in them.
+//
+// Lines holding a directive are never changed.
+const (
+	DirectiveDisable        = "pangu-disable"
+	DirectiveEnable         = "pangu-enable"
+	DirectiveIgnoreNextLine = "pangu-ignore-next-line"
+	DirectiveDisableFile    = "pangu-disable-file"
+)
+
+// Patterns of directives in the comments of various formats.
+var (
+	anyDirective    = directivePattern("#", "//", "/*", "<!--", "%", "..", ";", "--")
+	hashDirective   = directivePattern("#")
+	cDirective      = directivePattern("//", "/*")
+	slashDirective  = directivePattern("//")
+	markupDirective = directivePattern("<!--")
+	texDirective    = directivePattern("%")
+	rstDirective    = directivePattern("..")
+	vttDirective    = directivePattern("NOTE")
+)
+
+// directivePattern returns the pattern of a directive starting a comment
+// opened by one of openers, capturing the directive name without its
+// "pangu-" prefix.
+func directivePattern(openers ...string) *regexp.Regexp {
+	quoted := make([]string, len(openers))
+	for i, opener := range openers {
+		quoted[i] = regexp.QuoteMeta(opener)
+	}
+
+	return regexp.MustCompile(`(?:^|[ \t])(?:` + strings.Join(quoted, "|") +
+		`)[ \t]*pangu-(ignore-next-line|disable-file|disable|enable)(?:[ \t]|-->|\*/|$)`)
+}
+
+// A Suppression is a directive found in a file.
+type Suppression struct {
+	Line      int    // 1-based line number of the directive
+	Directive string // one of the Directive constants
+	Used      bool   // whether it kept at least one line from changing
+}
+
+// Suppress undoes the changes that spaced, the output of any of the
+// spacing modes for src, makes to the lines of src left alone by the
+// directives in src, and to the lines holding a directive. It returns the
+// result and the directives, with Used set on the ones that undid a
+// change. Directives may be in any of the comments recognized in plain
+// text. The format-aware modes of Spacer call it themselves; it is
+// exported for other modes built on SpacingText.
+func Suppress(src, spaced string) (string, []Suppression) {
+	return suppress(src, spaced, anyDirective)
+}
+
+// suppress is Suppress with directives matched by pattern, or none at all
+// if pattern is nil.
+func suppress(src, spaced string, pattern *regexp.Regexp) (string, []Suppression) {
+	if pattern == nil {
+		return spaced, nil
+	}
+	lines := splitLines(src)
+	sups, by := suppressions(lines, pattern)
+	if len(sups) == 0 {
+		return spaced, nil
+	}
+
+	starts := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		starts[i] = offset
+		offset += len(line)
+	}
+
+	var kept []edit
+	for _, e := range diff(src, spaced) {
+		i := sort.SearchInts(starts, e.start+1) - 1
+		switch {
+		case by[i] == notSuppressed:
+			kept = append(kept, e)
+		case by[i] >= 0:
+			sups[by[i]].Used = true
+		}
+	}
+
+	return apply(src, kept), sups
+}
+
+// Values of the line indexes returned by suppressions other than the
+// index of a directive.
+const (
+	notSuppressed = -1
+	directiveLine = -2
+)
+
+// suppressions returns the directives matched by pattern in lines and,
+// for each line, the index of the directive leaving it alone,
+// notSuppressed, or directiveLine if it holds a directive itself.
+func suppressions(lines []string, pattern *regexp.Regexp) ([]Suppression, []int) {
+	var sups []Suppression
+	for i, line := range lines {
+		if m := pattern.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+			sups = append(sups, Suppression{Line: i + 1, Directive: "pangu-" + m[1]})
+		}
+	}
+
+	by := make([]int, len(lines))
+	disableFile := -1
+	for i, sup := range sups {
+		if sup.Directive == DirectiveDisableFile {
+			disableFile = i
+			break
+		}
+	}
+
+	next := 0    // index into sups of the next directive
+	open := -1   // index of the pangu-disable in effect
+	ignore := -1 // index of the pangu-ignore-next-line in effect
+	for i := range lines {
+		if next < len(sups) && sups[next].Line == i+1 {
+			switch sups[next].Directive {
+			case DirectiveDisable:
+				if open < 0 {
+					open = next
+				}
+			case DirectiveEnable:
+				if open >= 0 {
+					sups[next].Used = true
+					open = -1
+				}
+			case DirectiveIgnoreNextLine:
+				ignore = next
+			}
+			by[i] = directiveLine
+			next++
+			continue
+		}
+
+		by[i] = open
+		if ignore >= 0 {
+			by[i] = ignore
+			ignore = -1
+		}
+		if disableFile >= 0 {
+			by[i] = disableFile
+		}
+	}
+
+	return sups, by
+}
+
+// suppressed returns spaced, the output of a mode of s for src, with the
+// directives matched by pattern in src honored. A Spacer made by
+// CheckMode only records pattern, leaving the directives to CheckMode.
+func (s *Spacer) suppressed(src, spaced []byte, pattern *regexp.Regexp) []byte {
+	if s.rawDirectives {
+		s.directives = pattern
+		return spaced
+	}
+	out, _ := suppress(string(src), string(spaced), pattern)
+
+	return []byte(out)
+}
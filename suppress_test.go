@@ -0,0 +1,144 @@
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type SuppressTestSuite struct {
+	suite.Suite
+}
+
+func TestSuppressTestSuite(t *testing.T) {
+	suite.Run(t, new(SuppressTestSuite))
+}
+
+func (suite *SuppressTestSuite) TestSpacingFile() {
+	var buf bytes.Buffer
+	err := pangu.SpacingFile("_fixtures/test_suppress.txt", &buf)
+	suite.Nil(err)
+
+	expected, err := ioutil.ReadFile("_fixtures/test_suppress.expected.txt")
+	checkError(err)
+	suite.Equal(string(expected), buf.String())
+}
+
+func (suite *SuppressTestSuite) TestDisableFile() {
+	text := "// pangu-disable-file\n與PM戰鬥的人\n"
+	problems, err := pangu.NewSpacer().Check(strings.NewReader(text))
+	suite.Nil(err)
+	suite.Empty(problems)
+}
+
+func (suite *SuppressTestSuite) TestCheck() {
+	problems, err := pangu.NewSpacer().CheckFile("_fixtures/test_suppress.txt")
+	suite.Nil(err)
+	suite.Equal([]pangu.Problem{
+		{Line: 1, Column: 2, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 3, End: 3, New: " "}, EndLine: 1, EndColumn: 2},
+		{Line: 1, Column: 4, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 5, End: 5, New: " "}, EndLine: 1, EndColumn: 4},
+		{Line: 5, Column: 6, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 111, End: 111, New: " "}, EndLine: 5, EndColumn: 6},
+		{Line: 8, Column: 1, Message: "unused pangu-disable", Rule: "unused-directive"},
+	}, problems)
+}
+
+func (suite *SuppressTestSuite) TestCheckClean() {
+	problems, err := pangu.NewSpacer().CheckFile("_fixtures/test_suppress.expected.txt")
+	suite.Nil(err)
+	suite.Equal([]pangu.Problem{
+		{Line: 8, Column: 1, Message: "unused pangu-disable", Rule: "unused-directive"},
+	}, problems)
+}
+
+func (suite *SuppressTestSuite) TestEdits() {
+	text := "與PM戰鬥的人，當你凝視著bug"
+	edits := pangu.Edits(text, pangu.SpacingText(text))
+	suite.Equal([]pangu.Edit{
+		{Start: 3, End: 3, New: " "},
+		{Start: 5, End: 5, New: " "},
+		{Start: 35, End: 35, New: " "},
+	}, edits)
+	suite.Equal("missing space", edits[0].Message())
+	suite.Equal("missing-space", edits[0].Rule())
+
+	edits = pangu.Edits("中文  abc\tdef", "中文 abc def")
+	suite.Equal([]pangu.Edit{
+		{Start: 7, End: 8, New: ""},
+		{Start: 11, End: 12, New: " "},
+	}, edits)
+	suite.Equal("extra space", edits[0].Message())
+	suite.Equal("wrong space", edits[1].Message())
+}
+
+func (suite *SuppressTestSuite) TestFormatModes() {
+	s := pangu.NewSpacer()
+
+	po := "# pangu-disable\nmsgid \"a\"\nmsgstr \"與PM戰鬥的人\"\n\n# pangu-enable\nmsgid \"b\"\nmsgstr \"與PM戰鬥的人\"\n"
+	suite.Equal("# pangu-disable\nmsgid \"a\"\nmsgstr \"與PM戰鬥的人\"\n\n# pangu-enable\nmsgid \"b\"\nmsgstr \"與 PM 戰鬥的人\"\n", string(s.SpacingPO([]byte(po))))
+
+	md := "<!-- pangu-disable -->\n與PM戰鬥的人\n<!-- pangu-enable -->\n當你凝視著bug\n"
+	suite.Equal("<!-- pangu-disable -->\n與PM戰鬥的人\n<!-- pangu-enable -->\n當你凝視著 bug\n", string(s.SpacingMarkdown([]byte(md))))
+
+	gosrc := "// pangu-disable-file\npackage main\n\n// 與PM戰鬥的人\n"
+	out, err := s.SpacingGo([]byte(gosrc))
+	suite.Nil(err)
+	suite.Equal(gosrc, string(out))
+
+	yml := "a: 與PM戰鬥的人 # pangu-ignore-next-line\nb: 與PM戰鬥的人\nc: 與PM戰鬥的人\n"
+	suite.Equal("a: 與PM戰鬥的人 # pangu-ignore-next-line\nb: 與PM戰鬥的人\nc: 與 PM 戰鬥的人\n", string(s.SpacingYAML([]byte(yml))))
+}
+
+func (suite *SuppressTestSuite) TestCheckMode() {
+	src := "# pangu-disable\nmsgid \"a\"\nmsgstr \"與PM戰鬥的人\"\n\n# pangu-enable\n# pangu-ignore-next-line\nmsgid \"b\"\nmsgstr \"與PM戰鬥的人\"\n"
+	problems, err := pangu.NewSpacer().CheckMode([]byte(src), func(s *pangu.Spacer, src []byte) ([]byte, error) {
+		return s.SpacingPO(src), nil
+	})
+	suite.Nil(err)
+	suite.Equal([]pangu.Problem{
+		{Line: 6, Column: 1, Message: "unused pangu-ignore-next-line", Rule: "unused-directive"},
+		{Line: 8, Column: 10, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 115, End: 115, New: " "}, EndLine: 8, EndColumn: 10},
+		{Line: 8, Column: 12, Message: "missing space", Rule: "missing-space", Fix: &pangu.Edit{Start: 117, End: 117, New: " "}, EndLine: 8, EndColumn: 12},
+	}, problems)
+}
+
+func (suite *SuppressTestSuite) TestSuppress() {
+	src := "中文abc\n// pangu-ignore-next-line\n中文abc\n"
+	out, sups := pangu.Suppress(src, pangu.NewSpacer().SpacingText(src))
+	suite.Equal("中文 abc\n// pangu-ignore-next-line\n中文abc\n", out)
+	suite.Equal([]pangu.Suppression{{Line: 2, Directive: pangu.DirectiveIgnoreNextLine, Used: true}}, sups)
+}
+
+func (suite *SuppressTestSuite) TestNotDirectives() {
+	s := pangu.NewSpacer()
+
+	// Mentions in prose and strings, and unknown directives.
+	for _, src := range []string{
+		"加上pangu-disable-file就好\n與PM戰鬥的人\n",
+		"echo \"# pangu-disable\"與PM\n與PM戰鬥的人\n",
+		"// pangu-disable-next-line\n與PM戰鬥的人\n",
+		"<!-- pangu-disabled -->\n與PM戰鬥的人\n",
+	} {
+		out := string(s.SpacingDocument([]byte(src)))
+		suite.True(strings.HasSuffix(out, "\n與 PM 戰鬥的人\n"), out)
+	}
+
+	// Comments of other formats.
+	md := "# pangu-disable\n與PM戰鬥的人\n"
+	suite.Equal("# pangu-disable\n與 PM 戰鬥的人\n", string(s.SpacingMarkdown([]byte(md))))
+	yml := "a: 加上pangu-disable-file就好\nb: 與PM戰鬥的人\n"
+	suite.Equal("a: 加上 pangu-disable-file 就好\nb: 與 PM 戰鬥的人\n", string(s.SpacingYAML([]byte(yml))))
+	gosrc := "package main\n\nvar a = \"// pangu-disable-file\"\n\n// 與PM戰鬥的人\n"
+	out, err := s.SpacingGo([]byte(gosrc))
+	suite.Nil(err)
+	suite.Contains(string(out), "// 與 PM 戰鬥的人\n")
+}
+
+func (suite *SuppressTestSuite) TestProblemEnd() {
+	problems := pangu.Problems("中文 \r\n  abc\n", "中文 abc\n")
+	suite.Len(problems, 1)
+	suite.Equal(pangu.Edit{Start: 7, End: 11, New: ""}, *problems[0].Fix)
+	suite.Equal([]int{1, 4, 2, 3}, []int{problems[0].Line, problems[0].Column, problems[0].EndLine, problems[0].EndColumn})
+}
@@ -0,0 +1,50 @@
+package pangu
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// FuncMap returns the functions for text/template templates, with the
+// default rules. See Spacer.FuncMap.
+func FuncMap() map[string]interface{} {
+	return defaultSpacer.FuncMap()
+}
+
+// HTMLFuncMap returns the functions for html/template templates, with
+// the default rules. See Spacer.HTMLFuncMap.
+func HTMLFuncMap() map[string]interface{} {
+	return defaultSpacer.HTMLFuncMap()
+}
+
+// FuncMap returns the functions to pass to the Funcs method of a
+// text/template template: "pangu" performs spacing on a string, as in
+// {{ .Title | pangu }}.
+func (s *Spacer) FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"pangu": s.SpacingText,
+	}
+}
+
+// HTMLFuncMap returns the functions to pass to the Funcs method of an
+// html/template template, where "pangu" keeps the safety of its argument:
+// a string is spaced and escaped as usual, a template.HTML value is spaced
+// with SpacingHTML and stays safe, and other safe values, such as
+// template.URL, are left alone. Values of other types are formatted
+// first, as by fmt.Sprint.
+func (s *Spacer) HTMLFuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"pangu": func(v interface{}) (interface{}, error) {
+			switch v := v.(type) {
+			case string:
+				return s.SpacingText(v), nil
+			case template.HTML:
+				out, err := s.SpacingHTML([]byte(v))
+				return template.HTML(out), err
+			case template.CSS, template.HTMLAttr, template.JS, template.JSStr, template.Srcset, template.URL:
+				return v, nil
+			}
+			return s.SpacingText(fmt.Sprint(v)), nil
+		},
+	}
+}
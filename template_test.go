@@ -0,0 +1,38 @@
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	htmltemplate "html/template"
+	"testing"
+	"text/template"
+)
+
+type TemplateTestSuite struct {
+	suite.Suite
+}
+
+func TestTemplateTestSuite(t *testing.T) {
+	suite.Run(t, new(TemplateTestSuite))
+}
+
+func (suite *TemplateTestSuite) TestFuncMap() {
+	t := template.Must(template.New("").Funcs(pangu.FuncMap()).Parse(`{{ .Title | pangu }}`))
+	var buf bytes.Buffer
+	suite.Nil(t.Execute(&buf, map[string]string{"Title": "與PM戰鬥的人<b>"}))
+	suite.Equal("與 PM 戰鬥的人 <b>", buf.String())
+}
+
+func (suite *TemplateTestSuite) TestHTMLFuncMap() {
+	t := htmltemplate.Must(htmltemplate.New("").Funcs(pangu.HTMLFuncMap()).Parse(
+		`<h1>{{ .Title | pangu }}</h1>{{ .Body | pangu }}<a href="{{ .URL | pangu }}">{{ .Count | pangu }}</a>`))
+	var buf bytes.Buffer
+	suite.Nil(t.Execute(&buf, map[string]interface{}{
+		"Title": "與PM戰鬥的人<b>",
+		"Body":  htmltemplate.HTML("<p>當你凝視著<b>bug</b></p>"),
+		"URL":   htmltemplate.URL("https://example.com/中文abc"),
+		"Count": 3,
+	}))
+	suite.Equal(`<h1>與 PM 戰鬥的人 &lt;b&gt;</h1><p>當你凝視著 <b>bug</b></p><a href="https://example.com/%e4%b8%ad%e6%96%87abc">3</a>`, buf.String())
+}
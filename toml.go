@@ -0,0 +1,200 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tomlBackslashed matches the words of literal strings holding a
+// backslash, such as Windows paths or regular expressions.
+var tomlBackslashed = regexp.MustCompile(`\S*\\\S*`)
+
+// SpacingTOML performs paranoid text spacing on a TOML document, such as
+// a Hugo site config. Only string values are changed, in arrays and
+// inline tables too, including multi-line strings. Keys, table headers,
+// comments, quoting style and escape sequences are left alone, byte for
+// byte, and so are the words of literal strings holding a backslash, such
+// as Windows paths or regular expressions.
+func (s *Spacer) SpacingTOML(src []byte) []byte {
+	t := &tomlWalker{src: string(src), spacer: s}
+	t.document()
+	t.out.WriteString(t.src[t.last:])
+
+	return s.suppressed(src, []byte(t.out.String()), hashDirective)
+}
+
+// tomlWalker walks a TOML document, copying it to out with its string
+// values spaced. It is lenient: whatever it does not understand is
+// copied as it is.
+type tomlWalker struct {
+	src    string
+	pos    int
+	last   int // end of the part of src already copied to out
+	out    strings.Builder
+	spacer *Spacer
+}
+
+func (t *tomlWalker) peek() byte {
+	if t.pos < len(t.src) {
+		return t.src[t.pos]
+	}
+	return 0
+}
+
+// skip skips whitespace, and newlines and comments if multiline is set.
+func (t *tomlWalker) skip(multiline bool) {
+	for t.pos < len(t.src) {
+		switch c := t.src[t.pos]; {
+		case c == ' ' || c == '\t':
+			t.pos++
+		case multiline && (c == '\r' || c == '\n'):
+			t.pos++
+		case multiline && c == '#':
+			t.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (t *tomlWalker) skipLine() {
+	if i := strings.IndexByte(t.src[t.pos:], '\n'); i >= 0 {
+		t.pos += i + 1
+	} else {
+		t.pos = len(t.src)
+	}
+}
+
+func (t *tomlWalker) document() {
+	for {
+		t.skip(true)
+		if t.pos >= len(t.src) {
+			return
+		}
+		if t.peek() == '[' {
+			// A table header, whose quoted keys are left alone.
+			t.skipLine()
+			continue
+		}
+		if !t.keyValue() {
+			t.skipLine()
+		}
+	}
+}
+
+// keyValue parses a key/value pair, reporting whether it found one.
+func (t *tomlWalker) keyValue() bool {
+	for t.pos < len(t.src) && t.peek() != '=' {
+		switch t.peek() {
+		case '"', '\'':
+			t.str(false)
+		case '\n', '#':
+			return false
+		default:
+			t.pos++
+		}
+	}
+	if t.pos >= len(t.src) {
+		return false
+	}
+	t.pos++
+	t.skip(false)
+	t.value()
+
+	return true
+}
+
+func (t *tomlWalker) value() {
+	switch t.peek() {
+	case '"', '\'':
+		t.str(true)
+	case '[':
+		t.pos++
+		for {
+			t.skip(true)
+			switch t.peek() {
+			case ']':
+				t.pos++
+				return
+			case ',':
+				t.pos++
+			case 0:
+				return
+			default:
+				t.value()
+			}
+		}
+	case '{':
+		t.pos++
+		for {
+			t.skip(false)
+			switch t.peek() {
+			case '}':
+				t.pos++
+				return
+			case ',':
+				t.pos++
+			case 0, '\n':
+				return
+			default:
+				if !t.keyValue() {
+					return
+				}
+			}
+		}
+	default:
+		// A number, boolean or date.
+		for t.pos < len(t.src) && !strings.ContainsRune(",]}#\r\n", rune(t.peek())) {
+			t.pos++
+		}
+	}
+}
+
+// str parses the string at pos, spacing it if space is set.
+func (t *tomlWalker) str(space bool) {
+	quote := t.src[t.pos : t.pos+1]
+	if strings.HasPrefix(t.src[t.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	start := t.pos + len(quote)
+
+	end := start
+	for end < len(t.src) {
+		if quote[0] == '"' && t.src[end] == '\\' {
+			end += 2
+			continue
+		}
+		if strings.HasPrefix(t.src[end:], quote) {
+			break
+		}
+		if len(quote) == 1 && t.src[end] == '\n' {
+			break
+		}
+		end++
+	}
+	if end >= len(t.src) || !strings.HasPrefix(t.src[end:], quote) {
+		// An unterminated string: leave the rest of the line alone.
+		t.pos = end
+		return
+	}
+	// Closing quotes of a multi-line string may be followed by up to two
+	// more quotes that belong to the content.
+	for len(quote) == 3 && strings.HasPrefix(t.src[end+1:], quote) {
+		end++
+	}
+	t.pos = end + len(quote)
+
+	if !space {
+		return
+	}
+	body := t.src[start:end]
+	var spaced string
+	if quote[0] == '"' {
+		spaced = t.spacer.spacingSegments(escapedSegments(body, nil))
+	} else {
+		spaced = t.spacer.spacingSegments(splitSegments(body, tomlBackslashed, func(raw string) string { return raw }))
+	}
+	t.out.WriteString(t.src[t.last:start])
+	t.out.WriteString(spaced)
+	t.last = end
+}
@@ -0,0 +1,47 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type TOMLTestSuite struct {
+	suite.Suite
+}
+
+func TestTOMLTestSuite(t *testing.T) {
+	suite.Run(t, new(TOMLTestSuite))
+}
+
+func (suite *TOMLTestSuite) TestSpacingTOML() {
+	src := "# 網站site設定\n" +
+		"title = \"與PM戰鬥的人\" # 註解comment\n" +
+		"\"鍵key\" = '當你凝視著bug'\n" +
+		"count = 3\n" +
+		"tags = [\"中文abc\", '中文def',\n" +
+		"  \"bug也凝視著你\\n中API\"]\n" +
+		"\n" +
+		"[params.\"作者author\"]\n" +
+		"inline = { \"名字name\" = \"陳上進Vinta\", age = 30 }\n" +
+		"body = \"\"\"\n" +
+		"第一行line\n" +
+		"第二行\\\"PM\\\"\"\"\"\n" +
+		"raw = '''路徑C:\\中文'''\n" +
+		"dir = '資料夾C:\\Users\\中文abc 在這裡abc'\n"
+
+	suite.Equal("# 網站site設定\n"+
+		"title = \"與 PM 戰鬥的人\" # 註解comment\n"+
+		"\"鍵key\" = '當你凝視著 bug'\n"+
+		"count = 3\n"+
+		"tags = [\"中文 abc\", '中文 def',\n"+
+		"  \"bug 也凝視著你\\n中 API\"]\n"+
+		"\n"+
+		"[params.\"作者author\"]\n"+
+		"inline = { \"名字name\" = \"陳上進 Vinta\", age = 30 }\n"+
+		"body = \"\"\"\n"+
+		"第一行 line\n"+
+		"第二行 \\\"PM\\\"\"\"\"\n"+
+		"raw = '''路徑C:\\中文'''\n"+
+		"dir = '資料夾C:\\Users\\中文abc 在這裡 abc'\n", string(pangu.NewSpacer().SpacingTOML([]byte(src))))
+}
@@ -0,0 +1,284 @@
+package pangu
+
+import (
+	"encoding/xml"
+	"html"
+	"strings"
+)
+
+// blockElements are the HTML elements that break the flow of text. The
+// rules see their tags as line breaks, so no space is ever inserted
+// between, say, two paragraphs.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "caption": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "rp": true, "rt": true, "section": true,
+	"table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// rawElements are the HTML elements whose content is never changed. The
+// rules see inline ones as the text they hold, and the others as line
+// breaks.
+var rawElements = map[string]bool{
+	"code": false, "kbd": false, "samp": false, "var": false,
+	"math": true, "pre": true, "script": true, "style": true,
+	"svg": true, "textarea": true,
+}
+
+// voidElements are the HTML elements that have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// textElements are the HTML elements whose content is read as text up to
+// their end tag, with no tags in it, and whether references are decoded
+// in it.
+var textElements = map[string]bool{
+	"iframe": false, "noembed": false, "noframes": false, "noscript": false,
+	"plaintext": false, "script": false, "style": false, "xmp": false,
+	"textarea": true, "title": true,
+}
+
+// SpacingXHTML performs paranoid text spacing on an XHTML document, such
+// as a content document of an EPUB. Only the text in <body> is changed,
+// with the context running across inline elements, so "<b>蒼蠅</b>Fly"
+// becomes "<b>蒼蠅</b> Fly". The content of <code>, <pre>, <script> and
+// the like, tags, references and everything else are left alone, byte for
+// byte. Documents without a <body> are returned unchanged.
+func (s *Spacer) SpacingXHTML(src []byte) ([]byte, error) {
+	tokens, err := xmlTokens(src)
+	if err != nil {
+		return nil, err
+	}
+	if out, ok := s.spacingBody(src, tokens); ok {
+		return out, nil
+	}
+
+	return src, nil
+}
+
+// SpacingHTML performs paranoid text spacing on HTML, either a document
+// with a <body>, as with SpacingXHTML, or a fragment such as the content
+// of a field in a CMS, of which all the text is changed the same way.
+// HTML is read the way browsers read it, so void elements such as <br>
+// may be left open and a "<" that does not start a tag is just text. The
+// error is always nil.
+func (s *Spacer) SpacingHTML(src []byte) ([]byte, error) {
+	tokens := htmlTokens(src)
+	if out, ok := s.spacingBody(src, tokens); ok {
+		return out, nil
+	}
+
+	return s.suppressed(src, []byte(s.spacingSegments(xhtmlSegments(tokens))), markupDirective), nil
+}
+
+// spacingBody performs spacing on the text in the <body> of a document
+// split into tokens, reporting whether it has one.
+func (s *Spacer) spacingBody(src []byte, tokens []xmlToken) ([]byte, bool) {
+	for i, tok := range tokens {
+		if start, ok := tok.Token.(xml.StartElement); ok && strings.EqualFold(start.Name.Local, "body") {
+			end := closing(tokens, i)
+
+			var out strings.Builder
+			for _, tok := range tokens[:i+1] {
+				out.WriteString(tok.raw)
+			}
+			out.WriteString(s.spacingSegments(xhtmlSegments(tokens[i+1 : end])))
+			for _, tok := range tokens[end:] {
+				out.WriteString(tok.raw)
+			}
+
+			return s.suppressed(src, []byte(out.String()), markupDirective), true
+		}
+	}
+
+	return nil, false
+}
+
+// htmlTokens splits HTML into tokens which, put together, give back the
+// HTML byte for byte. Void and self-closing elements get an empty
+// EndElement of their own, as with xmlTokens, and the other tokens, such
+// as comments, are only kept raw.
+func htmlTokens(src []byte) []xmlToken {
+	text := string(src)
+
+	var tokens []xmlToken
+	last := 0 // start of the text not in a token yet
+	addText := func(end int, decode bool) {
+		if end > last {
+			raw := text[last:end]
+			data := raw
+			if decode {
+				data = html.UnescapeString(raw)
+			}
+			tokens = append(tokens, xmlToken{xml.CharData(data), raw})
+		}
+		last = end
+	}
+
+	for i := 0; ; {
+		j := strings.IndexByte(text[i:], '<')
+		if j < 0 {
+			break
+		}
+		i += j
+		end, tok, selfClosing := htmlTag(text, i)
+		if end < 0 {
+			i++
+			continue
+		}
+		addText(i, true)
+		tokens = append(tokens, xmlToken{tok, text[i:end]})
+		last, i = end, end
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if selfClosing || voidElements[start.Name.Local] {
+			tokens = append(tokens, xmlToken{xml.EndElement{Name: start.Name}, ""})
+		}
+		if decode, ok := textElements[start.Name.Local]; ok && !selfClosing {
+			addText(htmlTextEnd(text, end, start.Name.Local), decode)
+			i = last
+		}
+	}
+	addText(len(text), true)
+
+	return tokens
+}
+
+// htmlTag reads the markup starting with the "<" at text[i], the way
+// browsers do, returning where it ends and its token: a StartElement or
+// an EndElement for tags, nil for comments, doctypes and the like. The
+// end is -1 if the "<" does not start any markup and is just text.
+func htmlTag(text string, i int) (end int, tok xml.Token, selfClosing bool) {
+	rest := text[i:]
+	n := 1 // length of "<" or "</"
+	switch {
+	case strings.HasPrefix(rest, "<!--"):
+		if j := strings.Index(rest[4:], "-->"); j >= 0 {
+			return i + 4 + j + 3, nil, false
+		}
+		return len(text), nil, false
+	case strings.HasPrefix(rest, "</") && len(rest) > 2 && isLetter(rest[2]):
+		n = 2
+	case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"), strings.HasPrefix(rest, "</") && len(rest) > 2:
+		// Doctypes, processing instructions and bogus comments.
+		if j := strings.IndexByte(rest, '>'); j >= 0 {
+			return i + j + 1, nil, false
+		}
+		return len(text), nil, false
+	case len(rest) < 2 || !isLetter(rest[1]):
+		return -1, nil, false
+	}
+
+	k := n
+	for k < len(rest) && !isHTMLSpace(rest[k]) && rest[k] != '/' && rest[k] != '>' {
+		k++
+	}
+	name := xml.Name{Local: strings.ToLower(rest[n:k])}
+
+	// Attributes, of which only the quoted values may hold a ">".
+	for k < len(rest) && rest[k] != '>' {
+		if rest[k] != '=' {
+			k++
+			continue
+		}
+		k++
+		for k < len(rest) && isHTMLSpace(rest[k]) {
+			k++
+		}
+		if k < len(rest) && (rest[k] == '"' || rest[k] == '\'') {
+			j := strings.IndexByte(rest[k+1:], rest[k])
+			if j < 0 {
+				k = len(rest)
+				break
+			}
+			k += j + 2
+		}
+	}
+	if k == len(rest) {
+		// A tag cut off by the end of the HTML is left alone.
+		return len(text), nil, false
+	}
+
+	if n == 2 {
+		return i + k + 1, xml.EndElement{Name: name}, false
+	}
+
+	return i + k + 1, xml.StartElement{Name: name}, rest[k-1] == '/'
+}
+
+// htmlTextEnd returns where the content of the text element name, which
+// starts at text[i], ends: at its end tag or at the end of the HTML.
+func htmlTextEnd(text string, i int, name string) int {
+	if name == "plaintext" {
+		return len(text)
+	}
+	for {
+		j := strings.Index(text[i:], "</")
+		if j < 0 {
+			return len(text)
+		}
+		i += j
+		k := i + 2 + len(name)
+		if k <= len(text) && strings.EqualFold(text[i+2:k], name) &&
+			(k == len(text) || isHTMLSpace(text[k]) || text[k] == '/' || text[k] == '>') {
+			return i
+		}
+		i += 2
+	}
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+// xhtmlSegments turns the content of an XHTML element into segments.
+func xhtmlSegments(tokens []xmlToken) []segment {
+	var segs []segment
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch t := tok.Token.(type) {
+		case xml.CharData:
+			if tok.isCDATA() {
+				segs = append(segs, cdataSegments(tok.raw)...)
+			} else {
+				segs = append(segs, splitSegments(tok.raw, xmlReference, html.UnescapeString)...)
+			}
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if block, ok := rawElements[name]; ok {
+				raw, text, end := element(tokens, i)
+				if block {
+					text = "\n"
+				}
+				segs = append(segs, opaque(raw, text))
+				i = end
+				continue
+			}
+			if blockElements[name] {
+				segs = append(segs, opaque(tok.raw, "\n"))
+				continue
+			}
+			segs = append(segs, segment{raw: tok.raw, open: true})
+		case xml.EndElement:
+			if blockElements[strings.ToLower(t.Name.Local)] {
+				segs = append(segs, opaque(tok.raw, "\n"))
+				continue
+			}
+			segs = append(segs, opaque(tok.raw, ""))
+		default:
+			segs = append(segs, opaque(tok.raw, ""))
+		}
+	}
+
+	return segs
+}
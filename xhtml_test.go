@@ -0,0 +1,78 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type XHTMLTestSuite struct {
+	suite.Suite
+}
+
+func TestXHTMLTestSuite(t *testing.T) {
+	suite.Run(t, new(XHTMLTestSuite))
+}
+
+func (suite *XHTMLTestSuite) TestSpacingXHTML() {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="zh-TW">
+<head><title>第1章Chapter</title></head>
+<body>
+<h1>第1章Chapter</h1>
+<p>當你凝視著<b>bug</b>，bug也凝視著你&amp;我</p><p>English段落</p>
+<p>執行<code>go test</code>指令<br/>然後看<a href="https://example.com/中文abc">結果Result</a></p>
+<pre>不要改PM</pre>
+<!-- 註解comment -->
+</body>
+</html>
+`
+	out, err := pangu.NewSpacer().SpacingXHTML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="zh-TW">
+<head><title>第1章Chapter</title></head>
+<body>
+<h1>第 1 章 Chapter</h1>
+<p>當你凝視著 <b>bug</b>，bug 也凝視著你 &amp; 我</p><p>English 段落</p>
+<p>執行 <code>go test</code> 指令<br/>然後看<a href="https://example.com/中文abc">結果 Result</a></p>
+<pre>不要改PM</pre>
+<!-- 註解comment -->
+</body>
+</html>
+`, string(out))
+}
+
+func (suite *XHTMLTestSuite) TestNoBody() {
+	src := `<svg><text>與PM戰鬥的人</text></svg>`
+	out, err := pangu.NewSpacer().SpacingXHTML([]byte(src))
+	suite.Nil(err)
+	suite.Equal(src, string(out))
+}
+
+func (suite *XHTMLTestSuite) TestSpacingHTML() {
+	s := pangu.NewSpacer()
+
+	out, err := s.SpacingHTML([]byte(`<p>當你凝視著<b>bug</b>，bug也凝視著你<br>然後看<img src="a.png">English段落</p><script>x("中文abc")</script>`))
+	suite.Nil(err)
+	suite.Equal(`<p>當你凝視著 <b>bug</b>，bug 也凝視著你<br>然後看 <img src="a.png">English 段落</p><script>x("中文abc")</script>`, string(out))
+
+	out, err = s.SpacingHTML([]byte("<html><head><title>第1章Chapter</title></head><body><p>第1章Chapter</p></body></html>"))
+	suite.Nil(err)
+	suite.Equal("<html><head><title>第1章Chapter</title></head><body><p>第 1 章 Chapter</p></body></html>", string(out))
+
+	// Ordinary HTML, which is not well-formed XML.
+	out, err = s.SpacingHTML([]byte(`<p class=note>如果a < b就用<code>x<br>y</code>吧</p><script>if (a < b) x("中文abc")</script><p>結束End`))
+	suite.Nil(err)
+	suite.Equal(`<p class=note>如果 a < b 就用 <code>x<br>y</code> 吧</p><script>if (a < b) x("中文abc")</script><p>結束 End`, string(out))
+
+	out, err = s.SpacingHTML([]byte("<!DOCTYPE html><html><body><p>第1章<br>Chapter<input type=text>中文abc</body></html>"))
+	suite.Nil(err)
+	suite.Equal("<!DOCTYPE html><html><body><p>第 1 章<br>Chapter <input type=text>中文 abc</body></html>", string(out))
+
+	out, err = s.SpacingHTML([]byte(`<P TITLE="a>b">中文abc<!-- 註解comment --><script>document.write("<b>中文abc</b>")</SCRIPT><textarea>中文abc</textarea>中文abc</P>`))
+	suite.Nil(err)
+	suite.Equal(`<P TITLE="a>b">中文 abc<!-- 註解comment --><script>document.write("<b>中文abc</b>")</SCRIPT><textarea>中文abc</textarea>中文 abc</P>`, string(out))
+}
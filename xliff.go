@@ -0,0 +1,73 @@
+package pangu
+
+import (
+	"encoding/xml"
+	"html"
+	"strings"
+)
+
+// SpacingXLIFF performs paranoid text spacing on an XLIFF 1.2 or 2.0
+// document. Only the content of <target> elements is changed; <source>
+// and everything else is left alone, byte for byte.
+//
+// Inline elements are opaque, but still give context to the text around
+// them: placeholders for codes, such as <x/> or <ph/>, are seen as a
+// word, so "共<x/>個" becomes "共 <x/> 個", while the tags of paired codes,
+// such as <g> or <pc>, and the markers of isolated ones, such as <bx/> or
+// <sc/>, are seen as nothing, the way markupSegments sees HTML tags.
+func (s *Spacer) SpacingXLIFF(src []byte) ([]byte, error) {
+	tokens, err := xmlTokens(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		out.WriteString(tokens[i].raw)
+		if tok, ok := tokens[i].Token.(xml.StartElement); ok && tok.Name.Local == "target" {
+			end := closing(tokens, i)
+			out.WriteString(s.spacingSegments(xliffSegments(tokens[i+1 : end])))
+			if end < len(tokens) {
+				out.WriteString(tokens[end].raw)
+			}
+			i = end
+		}
+	}
+
+	return s.suppressed(src, []byte(out.String()), markupDirective), nil
+}
+
+// xliffSegments turns the content of a <target> into segments.
+func xliffSegments(tokens []xmlToken) []segment {
+	var segs []segment
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch t := tok.Token.(type) {
+		case xml.CharData:
+			if tok.isCDATA() {
+				segs = append(segs, cdataSegments(tok.raw)...)
+			} else {
+				segs = append(segs, splitSegments(tok.raw, xmlReference, html.UnescapeString)...)
+			}
+		case xml.StartElement:
+			var seg segment
+			switch t.Name.Local {
+			case "x", "ph", "it":
+				seg.text = "0"
+			case "bx", "bpt", "sc", "sm":
+				seg.open = true
+			case "ex", "ept", "ec", "em":
+			default:
+				// A paired code holding text, such as <g> or <pc>.
+				segs = append(segs, segment{raw: tok.raw, open: true})
+				continue
+			}
+			seg.raw, _, i = element(tokens, i)
+			segs = append(segs, seg)
+		default:
+			segs = append(segs, opaque(tok.raw, ""))
+		}
+	}
+
+	return segs
+}
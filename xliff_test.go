@@ -0,0 +1,93 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type XLIFFTestSuite struct {
+	suite.Suite
+}
+
+func TestXLIFFTestSuite(t *testing.T) {
+	suite.Run(t, new(XLIFFTestSuite))
+}
+
+func (suite *XLIFFTestSuite) TestXLIFF12() {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" target-language="zh-TW" datatype="plaintext" original="app">
+    <body>
+      <trans-unit id="1">
+        <source>與PM戰鬥的人</source>
+        <target>與PM戰鬥的人</target>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>共<x id="1"/>個檔案</source>
+        <target>共<x id="1"/>個檔案，請看<g id="2">說明Doc</g>和<ph id="3">&lt;br/&gt;</ph>圖片</target>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>AT&amp;T</source>
+        <target state="new">前往<bx id="4"/>AT&amp;T<ex id="4"/>網站</target>
+      </trans-unit>
+      <trans-unit id="4">
+        <source>empty</source>
+        <target/>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+	out, err := pangu.NewSpacer().SpacingXLIFF([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" target-language="zh-TW" datatype="plaintext" original="app">
+    <body>
+      <trans-unit id="1">
+        <source>與PM戰鬥的人</source>
+        <target>與 PM 戰鬥的人</target>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>共<x id="1"/>個檔案</source>
+        <target>共 <x id="1"/> 個檔案，請看<g id="2">說明 Doc</g> 和 <ph id="3">&lt;br/&gt;</ph> 圖片</target>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>AT&amp;T</source>
+        <target state="new">前往 <bx id="4"/>AT&amp;T<ex id="4"/> 網站</target>
+      </trans-unit>
+      <trans-unit id="4">
+        <source>empty</source>
+        <target/>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`, string(out))
+}
+
+func (suite *XLIFFTestSuite) TestXLIFF20() {
+	src := `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="zh-TW">
+  <file id="f1">
+    <unit id="u1">
+      <segment>
+        <source>Hello <ph id="1"/>, see <pc id="2">the Doc</pc></source>
+        <target>你好<ph id="1"/>，請看<pc id="2">說明Doc</pc>文件</target>
+      </segment>
+    </unit>
+  </file>
+</xliff>`
+	out, err := pangu.NewSpacer().SpacingXLIFF([]byte(src))
+	suite.Nil(err)
+	suite.Equal(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="zh-TW">
+  <file id="f1">
+    <unit id="u1">
+      <segment>
+        <source>Hello <ph id="1"/>, see <pc id="2">the Doc</pc></source>
+        <target>你好 <ph id="1"/>，請看<pc id="2">說明 Doc</pc> 文件</target>
+      </segment>
+    </unit>
+  </file>
+</xliff>`, string(out))
+}
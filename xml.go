@@ -0,0 +1,117 @@
+package pangu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// xmlReference matches entity and character references.
+var xmlReference = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// markupTag matches a tag of markup embedded in text, such as HTML in a
+// CDATA section.
+var markupTag = regexp.MustCompile(`<[^<>]+>`)
+
+// An xmlToken is a token of an XML document along with its raw bytes.
+type xmlToken struct {
+	xml.Token
+	raw string
+}
+
+// xmlTokens splits an XML document into tokens which, put together, give
+// back the document byte for byte.
+func xmlTokens(src []byte) ([]xmlToken, error) {
+	d := xml.NewDecoder(bytes.NewReader(src))
+	d.Strict = false
+
+	var tokens []xmlToken
+	last := int64(0)
+	for {
+		tok, err := d.RawToken()
+		if tok == nil {
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			break
+		}
+		offset := d.InputOffset()
+		tokens = append(tokens, xmlToken{xml.CopyToken(tok), string(src[last:offset])})
+		last = offset
+	}
+	if int(last) < len(src) {
+		tokens = append(tokens, xmlToken{xml.CharData(src[last:]), string(src[last:])})
+	}
+
+	return tokens, nil
+}
+
+func (t xmlToken) isCDATA() bool {
+	return strings.HasPrefix(t.raw, "<![CDATA[")
+}
+
+// element returns the raw bytes and the character data of the element
+// started at tokens[i], along with the index of the token closing it.
+func element(tokens []xmlToken, i int) (raw, text string, end int) {
+	end = closing(tokens, i)
+	if end == len(tokens) {
+		end--
+	}
+	var r, t strings.Builder
+	for _, tok := range tokens[i : end+1] {
+		r.WriteString(tok.raw)
+		if data, ok := tok.Token.(xml.CharData); ok {
+			t.Write(data)
+		}
+	}
+
+	return r.String(), t.String(), end
+}
+
+// splitSegments splits text into literal segments and opaque ones for
+// the matches of re, seen by the rules as decode returns.
+func splitSegments(text string, re *regexp.Regexp, decode func(string) string) []segment {
+	var segs []segment
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] > last {
+			segs = append(segs, literal(text[last:loc[0]]))
+		}
+		raw := text[loc[0]:loc[1]]
+		segs = append(segs, opaque(raw, decode(raw)))
+		last = loc[1]
+	}
+	if last < len(text) {
+		segs = append(segs, literal(text[last:]))
+	}
+
+	return segs
+}
+
+// markupSegments splits text with embedded markup into literal text and
+// opaque tags, along with the references in it.
+func markupSegments(text string) []segment {
+	var segs []segment
+	for _, seg := range splitSegments(text, markupTag, func(string) string { return "" }) {
+		if !seg.literal {
+			seg.open = !strings.HasPrefix(seg.raw, "</")
+			segs = append(segs, seg)
+			continue
+		}
+		segs = append(segs, splitSegments(seg.raw, xmlReference, html.UnescapeString)...)
+	}
+
+	return segs
+}
+
+// cdataSegments splits a CDATA section, with the markup it may hold.
+func cdataSegments(raw string) []segment {
+	body := strings.TrimSuffix(strings.TrimPrefix(raw, "<![CDATA["), "]]>")
+	segs := []segment{opaque("<![CDATA[", "")}
+	segs = append(segs, markupSegments(body)...)
+
+	return append(segs, opaque("]]>", ""))
+}
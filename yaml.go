@@ -0,0 +1,169 @@
+package pangu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// yamlKey matches the indentation, sequence dashes and key of a YAML
+// line, leaving its value after the match.
+var yamlKey = regexp.MustCompile(`^([ \t]*(?:-[ \t]+)*)((?:"(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^ \t#'"{\[][^#]*?)[ \t]*:(?:[ \t]+|$))?`)
+
+// yamlProps matches the anchor and tag properties before a YAML value.
+var yamlProps = regexp.MustCompile(`^(?:[&!][^ \t]*[ \t]+)*`)
+
+// SpacingYAML performs paranoid text spacing on a YAML document, such as
+// a site config or a Rails-style locale file. Only scalar string values
+// are changed, plain, quoted and block scalars alike, and the quoted ones
+// in flow collections, as SpacingTOML does in arrays. Plain scalars that
+// spacing would turn into something else, such as "注意:Go語言" becoming a
+// mapping, are left alone, and so are keys, comments, quoting style,
+// anchors, aliases, tags and plain scalars in flow collections, byte for
+// byte.
+func (s *Spacer) SpacingYAML(src []byte) []byte {
+	var out strings.Builder
+	block := -1 // indentation of the block scalar in effect, if any
+	parent := 0 // indentation of the line that started it
+	for _, line := range splitLines(string(src)) {
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+		trimmed := strings.TrimLeft(body, " \t")
+		indent := len(body) - len(trimmed)
+
+		if block >= 0 && trimmed != "" && indent <= parent {
+			block = -1
+		}
+		if block >= 0 {
+			if trimmed == "" {
+				out.WriteString(line)
+				continue
+			}
+			if block == 0 {
+				block = indent
+			}
+			out.WriteString(body[:block] + s.SpacingText(body[block:]) + eol)
+			continue
+		}
+
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '%' ||
+			strings.HasPrefix(trimmed, "---") || strings.HasPrefix(trimmed, "...") {
+			out.WriteString(line)
+			continue
+		}
+
+		m := yamlKey.FindStringIndex(body)
+		start := m[1]
+		start += len(yamlProps.FindString(body[start:]))
+		value := body[start:]
+		if value == "" {
+			out.WriteString(line)
+			continue
+		}
+
+		switch value[0] {
+		case '|', '>':
+			block, parent = 0, indent
+			out.WriteString(line)
+			continue
+		case '"', '\'':
+			if end := yamlQuoteEnd(value, value[0]); end > 0 {
+				spaced := s.spacingYAMLQuoted(value[1:end], value[0])
+				out.WriteString(body[:start+1] + spaced + body[start+end:] + eol)
+				continue
+			}
+		case '[', '{':
+			out.WriteString(body[:start] + s.spacingYAMLFlow(value) + eol)
+			continue
+		case '*', '?':
+		default:
+			// A plain scalar runs until a comment.
+			end := len(value)
+			if i := strings.Index(value, " #"); i >= 0 {
+				end = i
+			}
+			if spaced := s.SpacingText(value[:end]); isYAMLPlain(spaced) {
+				out.WriteString(body[:start] + spaced + value[end:] + eol)
+				continue
+			}
+		}
+		out.WriteString(line)
+	}
+
+	return s.suppressed(src, []byte(out.String()), hashDirective)
+}
+
+// spacingYAMLQuoted performs spacing on body, the content of a string
+// quoted with quote.
+func (s *Spacer) spacingYAMLQuoted(body string, quote byte) string {
+	if quote == '"' {
+		return s.spacingSegments(escapedSegments(body, nil))
+	}
+
+	var segs []segment
+	for i, part := range strings.Split(body, "''") {
+		if i > 0 {
+			segs = append(segs, opaque("''", "'"))
+		}
+		segs = append(segs, literal(part))
+	}
+
+	return s.spacingSegments(segs)
+}
+
+// spacingYAMLFlow performs spacing on the quoted strings of value, a flow
+// collection, up to a comment or a string that does not close on this
+// line.
+func (s *Spacer) spacingYAMLFlow(value string) string {
+	var out strings.Builder
+	last := 0
+	prev := byte('[') // last character outside strings that is not a space
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == ' ' || c == '\t':
+			continue
+		case c == '#' && (value[i-1] == ' ' || value[i-1] == '\t'):
+			i = len(value)
+			continue
+		case (c == '"' || c == '\'') && strings.IndexByte("[{,:", prev) >= 0:
+			end := yamlQuoteEnd(value[i:], c)
+			if end < 0 {
+				i = len(value)
+				continue
+			}
+			out.WriteString(value[last : i+1])
+			out.WriteString(s.spacingYAMLQuoted(value[i+1:i+end], c))
+			last = i + end
+			i += end
+		}
+		prev = c
+	}
+	out.WriteString(value[last:])
+
+	return out.String()
+}
+
+// isYAMLPlain reports whether value still reads as the same plain scalar,
+// with no ": " making it a mapping and no " #" starting a comment.
+func isYAMLPlain(value string) bool {
+	return !strings.Contains(value, ": ") && !strings.Contains(value, ":\t") &&
+		!strings.Contains(value, " #") && !strings.Contains(value, "\t#") &&
+		!strings.HasSuffix(value, ":")
+}
+
+// yamlQuoteEnd returns the index of the quote closing the string that
+// starts value, or -1 if it does not close on this line.
+func yamlQuoteEnd(value string, quote byte) int {
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote && quote == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
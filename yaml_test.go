@@ -0,0 +1,71 @@
+package pangu_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"github.com/vinta/pangu"
+	"testing"
+)
+
+type YAMLTestSuite struct {
+	suite.Suite
+}
+
+func TestYAMLTestSuite(t *testing.T) {
+	suite.Run(t, new(YAMLTestSuite))
+}
+
+func (suite *YAMLTestSuite) TestSpacingYAML() {
+	src := "---\n" +
+		"# 設定config\n" +
+		"zh-TW:\n" +
+		"  title中文: 與PM戰鬥的人 # 註解comment\n" +
+		"  quoted: \"當你凝視著bug\\n中API\"\n" +
+		"  single: '它說''PM''是敵人'\n" +
+		"  base: &base 共3個檔案\n" +
+		"  copy: *base\n" +
+		"  tagged: !!str 版本v2\n" +
+		"  flow: [中文abc, 中文def]\n" +
+		"  quotedFlow: {a: \"中文abc\", b: ['它說''PM''', 中文def]} # 註解comment\n" +
+		"  list:\n" +
+		"    - 當你凝視著bug\n" +
+		"    - key: bug也凝視著你\n" +
+		"  body: |\n" +
+		"    第一行line\n" +
+		"\n" +
+		"      縮排indent\n" +
+		"  after: 與PM\n"
+
+	suite.Equal("---\n"+
+		"# 設定config\n"+
+		"zh-TW:\n"+
+		"  title中文: 與 PM 戰鬥的人 # 註解comment\n"+
+		"  quoted: \"當你凝視著 bug\\n中 API\"\n"+
+		"  single: '它說''PM'' 是敵人'\n"+
+		"  base: &base 共 3 個檔案\n"+
+		"  copy: *base\n"+
+		"  tagged: !!str 版本 v2\n"+
+		"  flow: [中文abc, 中文def]\n"+
+		"  quotedFlow: {a: \"中文 abc\", b: ['它說''PM''', 中文def]} # 註解comment\n"+
+		"  list:\n"+
+		"    - 當你凝視著 bug\n"+
+		"    - key: bug 也凝視著你\n"+
+		"  body: |\n"+
+		"    第一行 line\n"+
+		"\n"+
+		"      縮排 indent\n"+
+		"  after: 與 PM\n", string(pangu.NewSpacer().SpacingYAML([]byte(src))))
+}
+
+func (suite *YAMLTestSuite) TestEmptyBlockScalar() {
+	src := "empty: |\nafter: 與PM\n"
+	suite.Equal("empty: |\nafter: 與 PM\n", string(pangu.NewSpacer().SpacingYAML([]byte(src))))
+}
+
+func (suite *YAMLTestSuite) TestPlainScalarMeaning() {
+	for _, src := range []string{
+		"title: 注意:Go語言\n",
+		"tag: 中文#標籤\n",
+	} {
+		suite.Equal(src, string(pangu.NewSpacer().SpacingYAML([]byte(src))))
+	}
+}